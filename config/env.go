@@ -13,17 +13,42 @@ func init() {
 }
 
 type envConfigs struct {
-	HostClusterName string `mapstructure:"HostClusterName"`
-	KarmadaApi      string `mapstructure:"KarmadaApi"`
-	KarmadaToken    string `mapstructure:"KarmadaToken"`
-	NatsBucketName  string `mapstructure:"NatsBucketName"`
-	NatsId          string `mapstructure:"NatsId"`
-	NatsPassword    string `mapstructure:"NatsPassword"`
-	NatsSubjectName string `mapstructure:"NatsSubjectName"`
-	NatsUrl         string `mapstructure:"NatsUrl"`
-	VaultRoleId     string `mapstructure:"VaultRoleId"`
-	VaultSecretId   string `mapstructure:"VaultSecretId"`
-	VaultUrl        string `mapstructure:"VaultUrl"`
+	ClusterKubeconfig      string `mapstructure:"ClusterKubeconfig"`
+	ClusterSecretNamespace string `mapstructure:"ClusterSecretNamespace"`
+	ClusterSecretSelector  string `mapstructure:"ClusterSecretSelector"`
+	ClusterSource          string `mapstructure:"ClusterSource"`
+	CollectTimeout         int    `mapstructure:"CollectTimeout"`
+	CollectWorkers         int    `mapstructure:"CollectWorkers"`
+	FederatedWorkloadTopN  string `mapstructure:"FederatedWorkloadTopN"`
+	HealthIntervals        int    `mapstructure:"HealthIntervals"`
+	HistoryPath            string `mapstructure:"HistoryPath"`
+	HistoryRetention       string `mapstructure:"HistoryRetention"`
+	HostClusterName        string `mapstructure:"HostClusterName"`
+	KarmadaApi             string `mapstructure:"KarmadaApi"`
+	KarmadaCaCert          string `mapstructure:"KarmadaCaCert"`
+	KarmadaInsecure        bool   `mapstructure:"KarmadaInsecure"`
+	KarmadaProxy           string `mapstructure:"KarmadaProxy"`
+	KarmadaToken           string `mapstructure:"KarmadaToken"`
+	NamespaceTopN          string `mapstructure:"NamespaceTopN"`
+	NatsBucketName         string `mapstructure:"NatsBucketName"`
+	NatsId                 string `mapstructure:"NatsId"`
+	NatsPassword           string `mapstructure:"NatsPassword"`
+	NatsSubjectName        string `mapstructure:"NatsSubjectName"`
+	NatsUrl                string `mapstructure:"NatsUrl"`
+	VaultAuthMethod        string `mapstructure:"VaultAuthMethod"`
+	VaultAuthMount         string `mapstructure:"VaultAuthMount"`
+	VaultAuthRole          string `mapstructure:"VaultAuthRole"`
+	VaultCaCert            string `mapstructure:"VaultCaCert"`
+	VaultJwtPath           string `mapstructure:"VaultJwtPath"`
+	VaultKvFields          string `mapstructure:"VaultKvFields"`
+	VaultKvMount           string `mapstructure:"VaultKvMount"`
+	VaultKvPrefix          string `mapstructure:"VaultKvPrefix"`
+	VaultKvVersion         int    `mapstructure:"VaultKvVersion"`
+	VaultRoleId            string `mapstructure:"VaultRoleId"`
+	VaultSecretId          string `mapstructure:"VaultSecretId"`
+	VaultTokenPath         string `mapstructure:"VaultTokenPath"`
+	VaultUrl               string `mapstructure:"VaultUrl"`
+	VaultWatchInterval     int    `mapstructure:"VaultWatchInterval"`
 }
 
 func loadEnvVariables() (config *envConfigs) {
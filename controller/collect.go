@@ -0,0 +1,470 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"federation-metric-api/internal/karmada"
+	"federation-metric-api/internal/metricscollector"
+	"federation-metric-api/internal/util"
+	"federation-metric-api/model"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"log"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// collectTarget 은 수집 대상 클러스터와 API 서버 접근 경로이다.
+// proxy 가 true 이면 인증 정보 대신 Karmada 클러스터 프록시와 Karmada 토큰을 사용한다.
+type collectTarget struct {
+	cred        model.ClusterCredential
+	karmadaName string
+	proxy       bool
+}
+
+// collectJob 은 워커 풀에 전달되는 클러스터 단위 수집 작업이다.
+type collectJob struct {
+	index  int
+	target collectTarget
+}
+
+// collectStatus 는 수집 중 처음 발생한 오류와 그에 해당하는 수집 상태를 기록한다.
+type collectStatus struct {
+	state string
+	err   error
+}
+
+func (s *collectStatus) fail(state string, err error) {
+	if s.err != nil {
+		return
+	}
+	s.state = state
+	s.err = err
+}
+
+func (s *collectStatus) message() string {
+	if s.err == nil {
+		return ""
+	}
+	return s.err.Error()
+}
+
+// successTracker 는 클러스터별 마지막 수집 성공 시각을 수집 주기 간에 유지한다.
+type successTracker struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func newSuccessTracker() *successTracker {
+	return &successTracker{last: make(map[string]time.Time)}
+}
+
+// record 는 수집 상태가 ok 또는 degraded 이면 현재 시각을 성공 시각으로 갱신하고,
+// 해당 클러스터의 마지막 성공 시각을 반환한다.
+func (t *successTracker) record(clusterID, state string, now time.Time) *time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if state == model.CollectStateOk || state == model.CollectStateDegraded {
+		t.last[clusterID] = now
+	}
+	last, ok := t.last[clusterID]
+	if !ok {
+		return nil
+	}
+	return &last
+}
+
+var lastSuccess = newSuccessTracker()
+
+// inflight 는 아직 끝나지 않은 수집 고루틴이다. 시간 초과된 수집도 ctx 취소로 곧 끝나므로
+// 종료할 때와 테스트에서 훅을 되돌리기 전에 기다린다.
+var inflight sync.WaitGroup
+
+// withDeadline 은 fn 을 별도 고루틴에서 실행하고 timeout 안에 끝나지 않으면
+// false 를 반환한다. 응답하지 않는 API 서버가 수집 주기 전체를 붙잡지 않도록 한다.
+// fn 에 전달하는 ctx 는 반환과 함께 취소되므로 fn 의 API 호출도 함께 중단된다.
+func withDeadline[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) T) (T, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan T, 1)
+	inflight.Add(1)
+	go func() {
+		defer inflight.Done()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case v := <-done:
+		return v, true
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+// classifyError 는 Kubernetes API 호출 오류를 수집 상태로 변환한다.
+func classifyError(err error) string {
+	var urlErr *url.Error
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return model.CollectStateOk
+	case apierrors.IsUnauthorized(err), apierrors.IsForbidden(err):
+		return model.CollectStateUnauthorized
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		return model.CollectStateUnreachable
+	default:
+		return model.CollectStateDegraded
+	}
+}
+
+// classifyMetricsError 는 metrics.k8s.io 조회 오류를 수집 상태로 변환한다.
+// metrics-server 가 없으면 API 가 404 또는 503 을 반환한다.
+func classifyMetricsError(err error) string {
+	if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+		return model.CollectStateMetricsAPIMissing
+	}
+	return classifyError(err)
+}
+
+func checkRatio(cpu, mem float64) error {
+	for _, v := range []float64{cpu, mem} {
+		if math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			return fmt.Errorf("invalid usage ratio: cpu=%v memory=%v", cpu, mem)
+		}
+	}
+	return nil
+}
+
+// tlsClientConfig 는 CA 번들로 API 서버 인증서를 검증하는 설정을 만든다.
+// 명시적으로 허용된 경우에만 검증을 생략한다.
+func tlsClientConfig(caCert string, insecure bool) rest.TLSClientConfig {
+	if insecure {
+		return rest.TLSClientConfig{Insecure: true}
+	}
+	if caCert == "" {
+		return rest.TLSClientConfig{}
+	}
+	return rest.TLSClientConfig{CAData: []byte(caCert)}
+}
+
+// restConfigFor 는 수집 대상의 rest.Config 를 만든다. 인증 방식은 kubeconfig, 클라이언트 인증서,
+// bearer 토큰 순으로 적용되며 kubeconfig 의 exec 플러그인은 요청 시점에 실행된다.
+func restConfigFor(target collectTarget) (*rest.Config, error) {
+	if target.proxy {
+		return &rest.Config{
+			Host:            karmada.ClusterProxyURL(karmadaApi, target.karmadaName),
+			BearerToken:     karmadaToken,
+			Timeout:         collectTimeout,
+			TLSClientConfig: tlsClientConfig(karmadaCaCert, karmadaInsecure),
+		}, nil
+	}
+
+	ci := target.cred
+	if ci.Kubeconfig != "" {
+		cfg, err := clientcmd.RESTConfigFromKubeConfig([]byte(ci.Kubeconfig))
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+		}
+		cfg.Timeout = collectTimeout
+		return cfg, nil
+	}
+
+	cfg := &rest.Config{
+		Host:            ci.APIServerURL,
+		Timeout:         collectTimeout,
+		TLSClientConfig: tlsClientConfig(ci.CACert, ci.Insecure),
+	}
+	if ci.ClientCert != "" && ci.ClientKey != "" {
+		cfg.TLSClientConfig.CertData = []byte(ci.ClientCert)
+		cfg.TLSClientConfig.KeyData = []byte(ci.ClientKey)
+	} else {
+		cfg.BearerToken = ci.BearerToken
+	}
+	return cfg, nil
+}
+
+// insecureWarned 는 인증서 검증 생략 경고를 클러스터당 한 번만 남기기 위한 기록이다.
+var insecureWarned sync.Map
+
+func warnInsecure(clusterID string) {
+	if _, loaded := insecureWarned.LoadOrStore(clusterID, true); !loaded {
+		log.Printf("%s 클러스터 연결의 인증서 검증이 비활성화되었습니다 (insecure opt-in)", clusterID)
+	}
+}
+
+// clusterResult 는 클러스터 하나의 수집 결과와 워크로드 사용량 추적에 넘길 네임스페이스 사용량이다.
+type clusterResult struct {
+	status     model.ClusterStatus
+	namespaces []model.NamespaceUsage
+	usageKnown bool
+}
+
+// collectCluster 는 클러스터 하나의 헬스 체크, 노드 요약, 실시간 사용률, 요청 사용률을
+// 수집한다. 노드 목록, 노드 사용량, 파드 목록은 클러스터당 한 번만 조회해 각 지표 계산에 공유한다.
+func collectCluster(ctx context.Context, target collectTarget) model.ClusterStatus {
+	ci := target.cred
+	accessMode := model.AccessModeDirect
+	if target.proxy {
+		accessMode = model.AccessModeKarmadaProxy
+	}
+	clientset, insecure, clientErr := clients.get(target)
+	if insecure {
+		warnInsecure(ci.ClusterID)
+	}
+
+	// 시간 초과 후에도 고루틴이 끝나며 값을 남기지 않도록 워크로드 사용량은 결과로 받아 여기서 기록한다.
+	result, ok := withDeadline(ctx, collectTimeout, func(ctx context.Context) clusterResult {
+		var result clusterResult
+		cluster := model.ClusterStatus{ClusterId: ci.ClusterID, AccessMode: accessMode, InsecureTLS: insecure, Status: "Unknown"}
+		var status collectStatus
+
+		if clientErr != nil {
+			log.Printf("%s 클러스터 clientset 생성 실패: %v", ci.ClusterID, clientErr)
+			cluster.CollectState = model.CollectStateUnreachable
+			cluster.CollectError = clientErr.Error()
+			result.status = cluster
+			return result
+		}
+
+		//Status 구하는 로직
+		cluster.Status = NodeHealthCheckFunc(ctx, clientset)
+
+		node, err := GetNodeListFunc(ctx, clientset)
+		if err != nil {
+			status.fail(classifyError(err), err)
+		} else {
+			//노드 사용량은 실시간 사용률과 노드별 상태 계산에 공유한다.
+			nodeMetric, err := GetNodeMetricsFunc(ctx, clientset)
+			if err != nil {
+				status.fail(classifyMetricsError(err), err)
+			} else if ClCpuRatio, ClMemRatio, err := CollectMetricFunc(node, nodeMetric); err != nil {
+				status.fail(classifyMetricsError(err), err)
+			} else if err := checkRatio(ClCpuRatio, ClMemRatio); err != nil {
+				status.fail(model.CollectStateDegraded, err)
+			} else {
+				cluster.RealTimeUsage = model.NodeUsageFloat{
+					Cpu:    util.Round(ClCpuRatio, 2),
+					Memory: util.Round(ClMemRatio, 2),
+				}
+				cluster.RealTimeUsageCollected = true
+			}
+
+			//Node Summary 구하는 로직
+			totalNum, readyNum := NodeSummaryFunc(node)
+			cluster.NodeSummary = model.NodeSummary{
+				TotalNum: totalNum,
+				ReadyNum: readyNum,
+			}
+
+			//Node 별 상태 구하는 로직
+			nodes, err := CollectNodeStatusFunc(node, nodeMetric)
+			if err != nil {
+				status.fail(classifyMetricsError(err), err)
+			}
+			cluster.Nodes = nodes
+
+			//Pod 목록은 요청 사용률과 네임스페이스 사용량 계산에 공유한다.
+			pods, err := ListPodsFunc(ctx, clientset, node)
+			if err != nil {
+				status.fail(classifyError(err), err)
+			} else {
+				//Namespace 별 사용량 구하는 로직
+				if namespaceTopN >= 0 {
+					namespaces, err := CollectNamespaceFunc(ctx, clientset, pods)
+					if err != nil {
+						status.fail(classifyMetricsError(err), err)
+					}
+					result.namespaces, result.usageKnown = namespaces, err == nil
+					cluster.NamespaceCount = len(namespaces)
+					cluster.Namespaces = metricscollector.TopNamespaces(namespaces, namespaceTopN)
+				}
+
+				//RequestUsage 구하는 로직
+				requestCPURatio, requestMemRatio, err := CollectRequestMetricFunc(node, pods)
+				if err != nil {
+					status.fail(classifyError(err), err)
+				} else if err := checkRatio(requestCPURatio, requestMemRatio); err != nil {
+					status.fail(model.CollectStateDegraded, err)
+				} else {
+					cluster.RequestUsage = model.NodeUsageFloat{
+						Cpu:    util.Round(requestCPURatio, 2),
+						Memory: util.Round(requestMemRatio, 2),
+					}
+					cluster.RequestUsageCollected = true
+				}
+			}
+		}
+
+		if cluster.Status != "True" {
+			status.fail(model.CollectStateDegraded, fmt.Errorf("healthz status %s", cluster.Status))
+		}
+
+		cluster.CollectState = model.CollectStateOk
+		if status.err != nil {
+			log.Printf("%s 클러스터 수집 오류 (%s): %v", ci.ClusterID, status.state, status.err)
+			cluster.CollectState = status.state
+			cluster.CollectError = status.message()
+		}
+		result.status = cluster
+		return result
+	})
+	cluster := result.status
+	workloadUsage.record(usageKey(target), result.namespaces, result.usageKnown)
+	if !ok {
+		log.Printf("%s 클러스터 수집 시간 초과 (%s)", ci.ClusterID, collectTimeout)
+		cluster = model.ClusterStatus{
+			ClusterId:    ci.ClusterID,
+			AccessMode:   accessMode,
+			InsecureTLS:  insecure,
+			CollectState: model.CollectStateTimeout,
+			CollectError: fmt.Sprintf("collection timed out after %s", collectTimeout),
+			Status:       "Unknown",
+		}
+	}
+	cluster.LastSuccessTime = lastSuccess.record(ci.ClusterID, cluster.CollectState, time.Now().UTC())
+	return cluster
+}
+
+// collectWithRetry 는 연속으로 접속에 실패한 클러스터를 백오프 동안 다시 호출하지 않고
+// 마지막 실패 결과를 재사용한다. 접속 정보가 바뀌면 백오프를 기다리지 않는다.
+func collectWithRetry(ctx context.Context, target collectTarget) model.ClusterStatus {
+	now := time.Now()
+	fp := fingerprint(target)
+	if cluster, skipped := clusterRetry.skip(target.cred.ClusterID, fp, now); skipped {
+		return cluster
+	}
+	cluster := clusterRetry.record(collectCluster(ctx, target), fp, now)
+	clusterCollectDuration.WithLabelValues(cluster.ClusterId).Observe(time.Since(now).Seconds())
+	if cluster.CollectState != model.CollectStateOk {
+		clusterErrors.WithLabelValues(cluster.ClusterId, cluster.CollectState).Inc()
+	}
+	loop.beat(time.Now())
+	return cluster
+}
+
+// collectMembers 는 collectWorkers 개의 워커로 멤버 클러스터를 동시에 수집하고
+// 결과를 ClusterId 순으로 정렬해 반환한다.
+func collectMembers(ctx context.Context, targets []collectTarget) []model.MemberClusterStatus {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	workers := collectWorkers
+	if workers > len(targets) {
+		workers = len(targets)
+	}
+
+	jobs := make(chan collectJob)
+	memberClusterList := make([]model.MemberClusterStatus, len(targets))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				memberClusterList[job.index] = collectWithRetry(ctx, job.target)
+			}
+		}()
+	}
+	for i, target := range targets {
+		jobs <- collectJob{index: i, target: target}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(memberClusterList, func(i, j int) bool {
+		return memberClusterList[i].ClusterId < memberClusterList[j].ClusterId
+	})
+	return memberClusterList
+}
+
+// useKarmadaProxy 는 멤버 클러스터를 Karmada 클러스터 프록시로 수집할지 결정한다.
+// 설정에 지정된 클러스터이거나 Pull 모드 클러스터이면 프록시를 사용한다.
+func useKarmadaProxy(member karmada.MemberCluster) bool {
+	if karmadaApi == "" {
+		return false
+	}
+	return karmadaProxyClusters["*"] || karmadaProxyClusters[member.Name] || member.SyncMode == karmada.SyncModePull
+}
+
+// memberTargets 는 매칭 결과로부터 멤버 수집 대상을 만든다. 프록시로 수집하는 멤버는
+// 인증 정보가 없어도 수집 대상에 포함된다.
+func memberTargets(members []karmada.MemberCluster, creds []model.ClusterCredential, reconciliation model.Reconciliation) []collectTarget {
+	byName := make(map[string]karmada.MemberCluster, len(members))
+	for _, member := range members {
+		byName[member.Name] = member
+	}
+	credByID := make(map[string]model.ClusterCredential, len(creds))
+	for _, ci := range creds {
+		credByID[ci.ClusterID] = ci
+	}
+
+	var targets []collectTarget
+	for _, match := range reconciliation.Matched {
+		targets = append(targets, collectTarget{
+			cred:        credByID[match.ClusterId],
+			karmadaName: match.KarmadaName,
+			proxy:       useKarmadaProxy(byName[match.KarmadaName]),
+		})
+	}
+	for _, name := range reconciliation.MissingCredentials {
+		if useKarmadaProxy(byName[name]) {
+			targets = append(targets, collectTarget{
+				cred:        model.ClusterCredential{ClusterID: name},
+				karmadaName: name,
+				proxy:       true,
+			})
+		}
+	}
+	return targets
+}
+
+// collectClusters 는 호스트 클러스터와 Karmada 멤버로 확인된 클러스터를 병렬로 수집한다.
+func collectClusters(ctx context.Context, clusterInfos []model.ClusterCredential, memberClusters []karmada.MemberCluster) (model.HostClusterStatus, []model.MemberClusterStatus, model.Reconciliation) {
+	var hostCluster model.HostClusterStatus
+	var hostCred *model.ClusterCredential
+
+	for i, ci := range clusterInfos {
+		if ci.ClusterID == hostClusterName {
+			hostCred = &clusterInfos[i]
+			break
+		}
+	}
+	memberCreds, reconciliation := reconcileClusters(memberClusters, clusterInfos)
+	if len(reconciliation.MissingCredentials) > 0 || len(reconciliation.EndpointMismatches) > 0 {
+		log.Printf("클러스터 매칭 불일치: 인증 정보 없음 %v, 엔드포인트 불일치 %d건",
+			reconciliation.MissingCredentials, len(reconciliation.EndpointMismatches))
+	}
+
+	targets := memberTargets(memberClusters, memberCreds, reconciliation)
+	keep := append([]collectTarget(nil), targets...)
+	if hostCred != nil {
+		keep = append(keep, collectTarget{cred: *hostCred})
+	}
+	clients.retain(keep)
+	clusterRetry.retain(keep)
+	workloadUsage.retain(keep)
+
+	var wg sync.WaitGroup
+	if hostCred != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hostCluster = collectWithRetry(ctx, collectTarget{cred: *hostCred})
+		}()
+	}
+	memberClusterList := collectMembers(ctx, targets)
+	wg.Wait()
+
+	return hostCluster, memberClusterList, reconciliation
+}
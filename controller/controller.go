@@ -3,17 +3,20 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"federation-metric-api/config"
 	"federation-metric-api/internal/adapter"
+	"federation-metric-api/internal/history"
 	"federation-metric-api/internal/karmada"
 	"federation-metric-api/internal/metricscollector"
 	"federation-metric-api/internal/nats"
-	"federation-metric-api/internal/util"
 	"federation-metric-api/model"
 	outnats "github.com/nats-io/nats.go"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -27,15 +30,21 @@ type NatsClient interface {
 }
 
 var (
-	NewKarmadaClient = func() KarmadaClient { return karmada.NewClient() }
-	NewNatsClient    = func() NatsClient { return nats.NewClient() }
+	NewKarmadaClient = newKarmadaClient
+	NewNatsClient    = newNatsClient
 	GetClusterInfos  = adapter.GetClusterInfos
+	CredentialEvents = adapter.Events
 
 	NewKubeClient            = func(cfg *rest.Config) (kubernetes.Interface, error) { return kubernetes.NewForConfig(cfg) }
-	CollectMetricFunc        = metricscollector.CollectMetric
-	CollectRequestMetricFunc = metricscollector.CollectRequestMetric
+	GetNodeListFunc          = metricscollector.GetNodeList
+	GetNodeMetricsFunc       = metricscollector.GetNodeMetrics
+	ListPodsFunc             = metricscollector.ListPods
+	CollectMetricFunc        = metricscollector.CollectMetricFromNodes
+	CollectRequestMetricFunc = metricscollector.CollectRequestMetricFromNodes
 	NodeHealthCheckFunc      = metricscollector.NodeHealthCheck
-	NodeSummaryFunc          = metricscollector.NodeSummary
+	NodeSummaryFunc          = metricscollector.CountReady
+	CollectNodeStatusFunc    = metricscollector.CollectNodeStatusFromNodes
+	CollectNamespaceFunc     = metricscollector.CollectNamespaceUsageFromPods
 )
 
 var hostClusterName string
@@ -45,129 +54,306 @@ var repeatTime time.Duration = 30
 
 var natsSubjectName string
 
+const (
+	defaultCollectWorkers = 5
+	defaultCollectTimeout = 20 * time.Second
+)
+
+var collectWorkers = defaultCollectWorkers
+var collectTimeout = defaultCollectTimeout
+
+// namespaceTopN 은 클러스터별로 게시할 사용량 상위 네임스페이스, 워크로드 수이다. 0 이면 자르지 않고,
+// 음수이면 네임스페이스 집계를 하지 않는다.
+var namespaceTopN = 10
+
+// federatedWorkloadTopN 은 스냅샷에 게시할 사용량 상위 페더레이션 워크로드 수이다. 0 이하이면 자르지 않는다.
+var federatedWorkloadTopN = 50
+
+var karmadaApi string
+var karmadaToken string
+var karmadaCaCert string
+var karmadaInsecure bool
+
+// karmadaProxyClusters 는 Karmada 클러스터 프록시로 수집할 멤버 이름 목록이다. "*" 는 전체 멤버를 뜻한다.
+var karmadaProxyClusters = map[string]bool{}
+
 func init() {
 	hostClusterName = config.Env.HostClusterName
 	natsBucketName = config.Env.NatsBucketName
 	natsSubjectName = config.Env.NatsSubjectName
+	karmadaApi = config.Env.KarmadaApi
+	karmadaToken = config.Env.KarmadaToken
+	karmadaCaCert = config.Env.KarmadaCaCert
+	karmadaInsecure = config.Env.KarmadaInsecure
 
-}
+	for _, name := range strings.Split(config.Env.KarmadaProxy, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			karmadaProxyClusters[name] = true
+		}
+	}
 
-func RepeatMetric(ctx context.Context) {
-	ticker := time.NewTicker(repeatTime * time.Second)
-	defer ticker.Stop()
+	if config.Env.CollectWorkers > 0 {
+		collectWorkers = config.Env.CollectWorkers
+	}
+	if config.Env.HealthIntervals > 0 {
+		healthIntervals = config.Env.HealthIntervals
+	}
+	if config.Env.CollectTimeout > 0 {
+		collectTimeout = time.Duration(config.Env.CollectTimeout) * time.Second
+	}
+	namespaceTopN = parseTopN("NamespaceTopN", config.Env.NamespaceTopN, namespaceTopN)
+	federatedWorkloadTopN = parseTopN("FederatedWorkloadTopN", config.Env.FederatedWorkloadTopN, federatedWorkloadTopN)
+	if config.Env.HistoryRetention != "" {
+		if resolutions, err := history.ParseRetention(config.Env.HistoryRetention); err != nil {
+			log.Printf("%v, 기본 이력 보관 기간을 사용합니다", err)
+		} else {
+			History = history.NewStore(resolutions)
+		}
+	}
+	if config.Env.HistoryPath != "" {
+		historyPath = config.Env.HistoryPath
+		if err := History.Load(historyPath); err != nil {
+			log.Printf("이력 파일 복원 실패, 빈 이력으로 시작합니다: %v", err)
+		}
+	}
+}
 
-	karmadaClient := NewKarmadaClient()
-	natsClient := NewNatsClient()
-	if natsClient == nil {
+// parseTopN 은 상위 N 설정 값을 읽는다. 설정하지 않았거나 숫자가 아니면 fallback 을 사용하므로
+// 0 도 그대로 설정할 수 있다.
+func parseTopN(name, value string, fallback int) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("%s 값 %q 가 올바르지 않아 기본값 %d 을 사용합니다", name, value, fallback)
+		return fallback
+	}
+	return n
+}
 
+// newNatsClient 는 연결에 실패하면 nil 인터페이스를 반환한다.
+func newNatsClient() NatsClient {
+	if c := nats.NewClient(); c != nil {
+		return c
 	}
+	return nil
+}
 
-	kv, err := natsClient.CreateKeyValue(natsBucketName)
+// newKarmadaClient 는 list/watch 캐시 기반 클라이언트를 만들고, 실패하면 매번 직접 조회하는 클라이언트를 사용한다.
+func newKarmadaClient() KarmadaClient {
+	clusterCache, err := karmada.NewClusterCache()
 	if err != nil {
-		kv, err = natsClient.KeyValue(natsBucketName)
-		if err != nil {
-			log.Fatal(err)
-		}
+		log.Printf("Karmada Cluster 캐시 생성 실패, 직접 조회합니다: %v", err)
+		return karmada.NewClient()
 	}
+	return clusterCache
+}
 
-	for {
-		clusterInfos, err := GetClusterInfos()
-		memberClusters, err := karmadaClient.GetMemberClusters(ctx)
-		if err != nil {
-			log.Fatalf("Karmada member 클러스터 조회 실패: %v", err)
+// karmadaWatcher 는 멤버 구성을 감시하며 변경 시 신호를 보내는 Karmada 클라이언트이다.
+type karmadaWatcher interface {
+	Start(ctx context.Context)
+	Changes() <-chan struct{}
+}
+
+// openKeyValue 는 NATS 에 연결해 KV 버킷을 열거나 만든다. 연결된 클라이언트는 재시도 시 재사용한다.
+func openKeyValue(natsClient *NatsClient) (outnats.KeyValue, error) {
+	if *natsClient == nil {
+		*natsClient = NewNatsClient()
+		if *natsClient == nil {
+			return nil, errors.New("NATS 연결 실패")
 		}
+	}
+	kv, err := (*natsClient).CreateKeyValue(natsBucketName)
+	if err != nil {
+		kv, err = (*natsClient).KeyValue(natsBucketName)
+	}
+	return kv, err
+}
 
-		var ClCpuRatio, ClMemRatio float64
-		var hostCluster model.HostClusterStatus
-		var metricStatus model.MetricStatus
-
-		var memberClusterList []model.MemberClusterStatus
-		for _, ci := range clusterInfos {
-			if ci.ClusterID == hostClusterName {
-				cfg := &rest.Config{
-					Host:        ci.APIServerURL,
-					BearerToken: ci.BearerToken,
-					TLSClientConfig: rest.TLSClientConfig{
-						Insecure: true,
-					},
-				}
-				clientset, err := NewKubeClient(cfg)
-				if err != nil {
-					log.Printf("%s 클러스터 clientset 생성 실패: %v", ci.ClusterID, err)
-					continue
-				}
+// RepeatMetric 은 repeatTime 주기로 클러스터 지표를 수집해 NATS KV 에 게시한다.
+// 인증 정보 소스, Karmada, NATS 호출이 실패하면 백오프 후 다시 시도하며, 그동안은
+// 마지막으로 받은 데이터로 수집을 계속하고 실패 상태를 스냅샷의 dependencies 에 기록한다.
+// ctx 가 취소되면 진행 중인 수집 호출이 끝나기를 기다린 뒤 반환한다.
+func RepeatMetric(ctx context.Context) {
+	defer inflight.Wait()
+	ticker := time.NewTicker(repeatTime * time.Second)
+	defer ticker.Stop()
 
-				ClCpuRatio, ClMemRatio, err = CollectMetricFunc(clientset)
-				//Status 구하는 로직
-				hostCluster.Status = NodeHealthCheckFunc(clientset)
-				//Node Summary 구하는 로직
-				totalNum, readyNum := NodeSummaryFunc(clientset)
-				hostCluster.NodeSummary = model.NodeSummary{
-					TotalNum: totalNum,
-					ReadyNum: readyNum,
-				}
-				//RequestUsage 구하는 로직
-				requestCPURatio, requestMemRatio, _ := CollectRequestMetricFunc(clientset)
-				hostCluster.RequestUsage = model.NodeUsageFloat{
-					Cpu:    util.Round(requestCPURatio, 2), //fmt.Sprintf("%.2f", requestCPURatio),
-					Memory: util.Round(requestMemRatio, 2), //.Sprintf("%.2f", requestMemRatio),
-				}
+	karmadaClient := NewKarmadaClient()
+	var membershipChanges <-chan struct{}
+	if w, ok := karmadaClient.(karmadaWatcher); ok {
+		w.Start(ctx)
+		membershipChanges = w.Changes()
+	}
 
-				hostCluster.ClusterId = hostClusterName
-				hostCluster.RealTimeUsage = model.NodeUsageFloat{
-					Cpu:    util.Round(ClCpuRatio, 2), //fmt.Sprintf("%.2f", ClCpuRatio),
-					Memory: util.Round(ClMemRatio, 2), //fmt.Sprintf("%.2f", ClMemRatio),
-				}
+	// ResourceBinding 조회는 선택 기능이므로 지원하는 클라이언트에서만 별도 의존성으로 추적한다.
+	bindingSource, _ := karmadaClient.(bindingLister)
 
-				continue
-			}
-			for _, member := range memberClusters {
-				if member.Endpoint == ci.APIServerURL || ci.ClusterID != hostClusterName {
-					cfg := &rest.Config{
-						Host:        ci.APIServerURL,
-						BearerToken: ci.BearerToken,
-						TLSClientConfig: rest.TLSClientConfig{
-							Insecure: true,
-						},
-					}
-					clientset, err := NewKubeClient(cfg)
-					if err != nil {
-						log.Printf("%s 클러스터 clientset 생성 실패: %v", ci.ClusterID, err)
-						continue
-					}
-
-					ClCpuRatio, ClMemRatio, err = CollectMetricFunc(clientset)
-
-					memberClusterList = append(memberClusterList, model.MemberClusterStatus{
-						ClusterId: ci.ClusterID,
-						RealTimeUsage: model.NodeUsageFloat{
-							Cpu:    util.Round(ClCpuRatio, 2), //fmt.Sprintf("%.2f", ClCpuRatio),
-							Memory: util.Round(ClMemRatio, 2), // fmt.Sprintf("%.2f", ClMemRatio),
-						},
-					})
-
-					break
+	var pub publisher
+
+	credentialEvents := CredentialEvents()
+	var clusterInfos []model.ClusterCredential
+	var memberClusters []karmada.MemberCluster
+	var resourceBindings []karmada.ResourceBinding
+	refresh := false
+
+	for {
+		now := time.Now()
+		loop.beat(now)
+		if dependencies.due(DependencyCredentials, now) {
+			if infos, err := GetClusterInfos(); err != nil {
+				// 일부 소스만 실패했으면 실패한 소스의 마지막 결과로 병합된 인증 정보를 사용한다.
+				var partial *adapter.PartialError
+				var next time.Time
+				if errors.As(err, &partial) {
+					clusterInfos = infos
+					next = dependencies.partial(DependencyCredentials, err, now)
+				} else {
+					next = dependencies.failure(DependencyCredentials, err, now)
 				}
+				log.Printf("클러스터 인증 정보 조회 실패, 마지막 인증 정보 %d개를 사용합니다 (재시도 %s): %v",
+					len(clusterInfos), next.Format(time.RFC3339), err)
+			} else {
+				clusterInfos = infos
+				dependencies.success(DependencyCredentials, now)
+			}
+		}
+		if dependencies.due(DependencyKarmada, now) {
+			if members, err := karmadaClient.GetMemberClusters(ctx); err != nil {
+				next := dependencies.failure(DependencyKarmada, err, now)
+				log.Printf("Karmada member 클러스터 조회 실패, 마지막으로 알려진 멤버 %d개를 사용합니다 (재시도 %s): %v",
+					len(memberClusters), next.Format(time.RFC3339), err)
+			} else {
+				memberClusters = members
+				dependencies.success(DependencyKarmada, now)
+			}
+		}
+		if bindingSource != nil && dependencies.due(DependencyBindings, now) {
+			if bindings, err := bindingSource.GetResourceBindings(ctx); err != nil {
+				next := dependencies.failure(DependencyBindings, err, now)
+				log.Printf("Karmada ResourceBinding 조회 실패, 마지막으로 알려진 바인딩 %d개를 사용합니다 (재시도 %s): %v",
+					len(resourceBindings), next.Format(time.RFC3339), err)
+			} else {
+				resourceBindings = bindings
+				dependencies.success(DependencyBindings, now)
+			}
+		}
+
+		hostCluster, memberClusterList, reconciliation := collectClusters(ctx, clusterInfos, memberClusters)
+		cycleDuration.Observe(time.Since(now).Seconds())
+		snapshot := func() model.MetricStatus {
+			workloads := workloadUsage.federatedWorkloads(resourceBindings)
+			return model.MetricStatus{
+				HostClusterStatus:      hostCluster,
+				MemberClusterStatus:    memberClusterList,
+				Reconciliation:         reconciliation,
+				KarmadaClusters:        karmadaClusterViews(memberClusters),
+				FederatedWorkloads:     topWorkloads(workloads, federatedWorkloadTopN),
+				FederatedWorkloadCount: len(workloads),
+				Dependencies:           dependencies.statuses(),
+				Time:                   time.Now().UTC(),
 			}
 		}
 
+		// 이벤트로 다시 수집한 결과는 다음 주기를 기다리지 않고 바로 게시한다. 같은 결과를 주기에 다시 게시하지 않는다.
+		published := false
+		if refresh {
+			pub.publish(snapshot())
+			refresh, published = false, true
+		}
+
 		select {
 		case <-ctx.Done():
 			return
+		case ev := <-credentialEvents:
+			// 다음 주기를 기다리지 않고 바로 다시 수집해 추가, 변경된 클러스터를 반영한다.
+			log.Printf("클러스터 인증 정보 변경(%s): %s", ev.Type, ev.ClusterID)
+			clusterRetry.forget(ev.ClusterID)
+			drainEvents(credentialEvents)
+			refresh = true
+		case <-membershipChanges:
+			log.Printf("Karmada 멤버 구성 변경을 감지해 다시 수집합니다")
+			refresh = true
 		case <-ticker.C:
-			metricStatus = model.MetricStatus{
-				HostClusterStatus:   hostCluster,
-				MemberClusterStatus: memberClusterList,
-				Time:                time.Now().UTC(),
+			if !published {
+				pub.publish(snapshot())
 			}
-			data, _ := json.Marshal(metricStatus)
+		}
+	}
+}
 
-			_, err = kv.Put(natsSubjectName, data)
-			if err != nil {
-				log.Printf("Failed to send metrics: %v", err)
-			} else {
-				log.Printf("Metric transfer complete")
-			}
+// publisher 는 수집 결과를 latest, 이력, NATS KV 에 게시한다. NATS 는 게시할 때 연결하며 실패하면 백오프 후 다시 연결한다.
+type publisher struct {
+	natsClient   NatsClient
+	kv           outnats.KeyValue
+	historySaved time.Time
+}
+
+func (p *publisher) publish(metricStatus model.MetricStatus) {
+	now := time.Now()
+	if p.kv == nil && dependencies.due(DependencyNats, now) {
+		var err error
+		if p.kv, err = openKeyValue(&p.natsClient); err != nil {
+			p.kv = nil
+			next := dependencies.failure(DependencyNats, err, now)
+			log.Printf("NATS KV 버킷 준비 실패 (재시도 %s): %v", next.Format(time.RFC3339), err)
+		} else {
+			dependencies.success(DependencyNats, now)
+		}
+		metricStatus.Dependencies = dependencies.statuses()
+	}
+
+	latest.set(metricStatus)
+	History.Record(metricStatus)
+	if historyPath != "" && now.Sub(p.historySaved) >= historySaveInterval {
+		p.historySaved = now
+		if err := SaveHistory(); err != nil {
+			log.Printf("이력 파일 저장 실패: %v", err)
+		}
+	}
+
+	if p.kv == nil {
+		log.Printf("NATS KV 를 사용할 수 없어 이번 주기 지표 게시를 건너뜁니다")
+		return
+	}
+	data, _ := json.Marshal(kvPayload(metricStatus))
+
+	if _, err := p.kv.Put(natsSubjectName, data); err != nil {
+		kvPutFailures.Inc()
+		dependencies.failure(DependencyNats, err, now)
+		log.Printf("Failed to send metrics: %v", err)
+	} else {
+		dependencies.success(DependencyNats, now)
+		loop.published(time.Now())
+		log.Printf("Metric transfer complete")
+	}
+}
+
+// kvPayload 는 NATS KV 에 게시할 스냅샷이다. 클러스터 규모에 비례해 커지는 노드별 상태는 빼고
+// REST API 로만 제공한다.
+func kvPayload(metricStatus model.MetricStatus) model.MetricStatus {
+	metricStatus.HostClusterStatus.Nodes = nil
+	members := make([]model.MemberClusterStatus, len(metricStatus.MemberClusterStatus))
+	for i, member := range metricStatus.MemberClusterStatus {
+		member.Nodes = nil
+		members[i] = member
+	}
+	metricStatus.MemberClusterStatus = members
+	return metricStatus
+}
+
+// drainEvents 는 한 번의 재수집으로 함께 반영될 대기 중인 이벤트를 비운다. 변경된 클러스터는
+// 이전 인증 정보로 실패한 백오프를 기다리지 않고 바로 수집한다.
+func drainEvents(events <-chan model.CredentialEvent) {
+	for {
+		select {
+		case ev := <-events:
+			log.Printf("클러스터 인증 정보 변경(%s): %s", ev.Type, ev.ClusterID)
+			clusterRetry.forget(ev.ClusterID)
+		default:
+			return
 		}
 	}
 }
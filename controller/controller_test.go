@@ -3,13 +3,21 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net/url"
+	"sync"
 	"testing"
 	"time"
 
 	"federation-metric-api/internal/karmada"
 	"federation-metric-api/model"
 	outnats "github.com/nats-io/nats.go"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
 )
 
@@ -23,14 +31,23 @@ func (f *fakeKarm) GetMemberClusters(ctx context.Context) ([]karmada.MemberClust
 
 type fakeKV struct {
 	outnats.KeyValue
+	mu   sync.Mutex
 	puts [][]byte
 }
 
 func (f *fakeKV) Put(key string, val []byte) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.puts = append(f.puts, val)
 	return 1, nil
 }
 
+func (f *fakeKV) stored() [][]byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]byte(nil), f.puts...)
+}
+
 type fakeNats struct {
 	kv outnats.KeyValue
 }
@@ -75,12 +92,13 @@ func TestRepeatMetric_UsesNatsKVPut(t *testing.T) {
 	cancel()
 	time.Sleep(200 * time.Millisecond)
 
-	if len(fakeStore.puts) == 0 {
+	puts := fakeStore.stored()
+	if len(puts) == 0 {
 		t.Fatalf("expected at least one KV Put call")
 	}
 
 	var ms model.MetricStatus
-	if err := json.Unmarshal(fakeStore.puts[0], &ms); err != nil {
+	if err := json.Unmarshal(puts[0], &ms); err != nil {
 		t.Fatalf("invalid MetricStatus JSON stored in KV: %v", err)
 	}
 }
@@ -96,20 +114,28 @@ func TestRepeatMetric_FillsHostAndMemberMetrics(t *testing.T) {
 	oldNats := NewNatsClient
 	oldGetClusters := GetClusterInfos
 	oldKube := NewKubeClient
+	oldNodes := GetNodeListFunc
+	oldNodeMetrics := GetNodeMetricsFunc
 	oldCollect := CollectMetricFunc
 	oldCollectReq := CollectRequestMetricFunc
 	oldHealth := NodeHealthCheckFunc
 	oldSummary := NodeSummaryFunc
+	oldNodeStatus := CollectNodeStatusFunc
+	oldNamespaces := CollectNamespaceFunc
 
 	defer func() {
 		NewKarmadaClient = oldKarm
 		NewNatsClient = oldNats
 		GetClusterInfos = oldGetClusters
 		NewKubeClient = oldKube
+		GetNodeListFunc = oldNodes
+		GetNodeMetricsFunc = oldNodeMetrics
 		CollectMetricFunc = oldCollect
 		CollectRequestMetricFunc = oldCollectReq
 		NodeHealthCheckFunc = oldHealth
 		NodeSummaryFunc = oldSummary
+		CollectNodeStatusFunc = oldNodeStatus
+		CollectNamespaceFunc = oldNamespaces
 	}()
 
 	hostClusterName = "host-1"
@@ -132,18 +158,30 @@ func TestRepeatMetric_FillsHostAndMemberMetrics(t *testing.T) {
 
 	NewKubeClient = func(cfg *rest.Config) (kubernetes.Interface, error) { return nil, nil }
 
-	CollectMetricFunc = func(client kubernetes.Interface) (float64, float64, error) {
+	GetNodeListFunc = func(ctx context.Context, client kubernetes.Interface) (model.NodeModel, error) {
+		return model.NodeModel{}, nil
+	}
+	GetNodeMetricsFunc = func(ctx context.Context, client kubernetes.Interface) (model.NodeMetricModel, error) {
+		return model.NodeMetricModel{}, nil
+	}
+	CollectMetricFunc = func(node model.NodeModel, nodeMetric model.NodeMetricModel) (float64, float64, error) {
 		return 10.0, 20.0, nil
 	}
-	CollectRequestMetricFunc = func(client kubernetes.Interface) (float64, float64, error) {
+	CollectRequestMetricFunc = func(node model.NodeModel, pods []corev1.Pod) (float64, float64, error) {
 		return 30.0, 40.0, nil
 	}
-	NodeHealthCheckFunc = func(client kubernetes.Interface) string {
+	NodeHealthCheckFunc = func(ctx context.Context, client kubernetes.Interface) string {
 		return "Healthy"
 	}
-	NodeSummaryFunc = func(client kubernetes.Interface) (int, int) {
+	NodeSummaryFunc = func(node model.NodeModel) (int, int) {
 		return 5, 4
 	}
+	CollectNodeStatusFunc = func(node model.NodeModel, nodeMetric model.NodeMetricModel) ([]model.NodeStatus, error) {
+		return nil, nil
+	}
+	CollectNamespaceFunc = func(ctx context.Context, client kubernetes.Interface, pods []corev1.Pod) ([]model.NamespaceUsage, error) {
+		return nil, nil
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	go RepeatMetric(ctx)
@@ -152,12 +190,13 @@ func TestRepeatMetric_FillsHostAndMemberMetrics(t *testing.T) {
 	cancel()
 	time.Sleep(200 * time.Millisecond)
 
-	if len(fakeStore.puts) == 0 {
+	puts := fakeStore.stored()
+	if len(puts) == 0 {
 		t.Fatalf("expected at least one KV Put call")
 	}
 
 	var ms model.MetricStatus
-	if err := json.Unmarshal(fakeStore.puts[0], &ms); err != nil {
+	if err := json.Unmarshal(puts[0], &ms); err != nil {
 		t.Fatalf("invalid MetricStatus JSON stored in KV: %v", err)
 	}
 
@@ -176,4 +215,520 @@ func TestRepeatMetric_FillsHostAndMemberMetrics(t *testing.T) {
 	if ms.MemberClusterStatus[0].ClusterId != "member-1" {
 		t.Fatalf("unexpected member cluster id: %q", ms.MemberClusterStatus[0].ClusterId)
 	}
+	if ms.MemberClusterStatus[0].Status != "Healthy" {
+		t.Fatalf("expected member Status 'Healthy', got %q", ms.MemberClusterStatus[0].Status)
+	}
+	if ms.MemberClusterStatus[0].NodeSummary.TotalNum != 5 || ms.MemberClusterStatus[0].NodeSummary.ReadyNum != 4 {
+		t.Fatalf("unexpected member NodeSummary: %+v", ms.MemberClusterStatus[0].NodeSummary)
+	}
+	if ms.MemberClusterStatus[0].RequestUsage.Cpu != 30.0 || ms.MemberClusterStatus[0].RequestUsage.Memory != 40.0 {
+		t.Fatalf("unexpected member RequestUsage: %+v", ms.MemberClusterStatus[0].RequestUsage)
+	}
+}
+
+// flakyKarm 은 첫 조회 이후 계속 실패하는 Karmada 클라이언트이다.
+type flakyKarm struct {
+	mu       sync.Mutex
+	calls    int
+	clusters []karmada.MemberCluster
+}
+
+func (f *flakyKarm) GetMemberClusters(ctx context.Context) ([]karmada.MemberCluster, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls > 1 {
+		return nil, errors.New("karmada unavailable")
+	}
+	return f.clusters, nil
+}
+
+func TestRepeatMetric_KeepsLastKnownMembershipOnKarmadaError(t *testing.T) {
+	restore := stubCollectors()
+	oldRepeat := repeatTime
+	oldKarm := NewKarmadaClient
+	oldNats := NewNatsClient
+	oldGet := GetClusterInfos
+	oldHost := hostClusterName
+	defer func() {
+		restore()
+		repeatTime = oldRepeat
+		NewKarmadaClient = oldKarm
+		NewNatsClient = oldNats
+		GetClusterInfos = oldGet
+		hostClusterName = oldHost
+	}()
+
+	repeatTime = 1
+	hostClusterName = "host-1"
+	fakeStore := &fakeKV{}
+	karm := &flakyKarm{clusters: []karmada.MemberCluster{{Name: "member-1", Endpoint: "https://member"}}}
+	NewKarmadaClient = func() KarmadaClient { return karm }
+	NewNatsClient = func() NatsClient { return &fakeNats{kv: fakeStore} }
+	GetClusterInfos = func() ([]model.ClusterCredential, error) {
+		return []model.ClusterCredential{{ClusterID: "member-1", APIServerURL: "https://member", BearerToken: "tm"}}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RepeatMetric(ctx)
+	}()
+	time.Sleep(2500 * time.Millisecond)
+	cancel()
+	<-done
+
+	puts := fakeStore.stored()
+	if len(puts) < 2 {
+		t.Fatalf("expected the loop to keep publishing after a Karmada failure, got %d puts", len(puts))
+	}
+	var ms model.MetricStatus
+	if err := json.Unmarshal(puts[len(puts)-1], &ms); err != nil {
+		t.Fatalf("invalid MetricStatus JSON stored in KV: %v", err)
+	}
+	if len(ms.MemberClusterStatus) != 1 || ms.MemberClusterStatus[0].ClusterId != "member-1" {
+		t.Fatalf("expected last known member to be collected, got %+v", ms.MemberClusterStatus)
+	}
+}
+
+func TestRepeatMetric_PublishesEventDrivenCollectionImmediately(t *testing.T) {
+	restore := stubCollectors()
+	oldRepeat := repeatTime
+	oldKarm := NewKarmadaClient
+	oldNats := NewNatsClient
+	oldGet := GetClusterInfos
+	oldEvents := CredentialEvents
+	oldDeps := dependencies
+	oldHost := hostClusterName
+	oldLatest := latest
+	defer func() {
+		restore()
+		repeatTime = oldRepeat
+		NewKarmadaClient = oldKarm
+		NewNatsClient = oldNats
+		GetClusterInfos = oldGet
+		CredentialEvents = oldEvents
+		dependencies = oldDeps
+		hostClusterName = oldHost
+		latest = oldLatest
+	}()
+
+	// 주기가 오기 전에 이벤트만으로 게시되는지 확인한다.
+	repeatTime = 3600
+	hostClusterName = "host-1"
+	latest = &snapshotStore{}
+	dependencies = newDependencyTracker(DependencyCredentials, DependencyKarmada, DependencyNats)
+	fakeStore := &fakeKV{}
+	events := make(chan model.CredentialEvent, 1)
+	NewKarmadaClient = func() KarmadaClient {
+		return &fakeKarm{clusters: []karmada.MemberCluster{{Name: "member-1", Endpoint: "https://member"}}}
+	}
+	NewNatsClient = func() NatsClient { return &fakeNats{kv: fakeStore} }
+	CredentialEvents = func() <-chan model.CredentialEvent { return events }
+	var mu sync.Mutex
+	creds := []model.ClusterCredential{{ClusterID: "host-1", APIServerURL: "https://host"}}
+	GetClusterInfos = func() ([]model.ClusterCredential, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return creds, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RepeatMetric(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	if puts := fakeStore.stored(); len(puts) != 0 {
+		t.Fatalf("expected nothing to be published before the first tick, got %d puts", len(puts))
+	}
+
+	mu.Lock()
+	creds = append(creds, model.ClusterCredential{ClusterID: "member-1", APIServerURL: "https://member"})
+	mu.Unlock()
+	events <- model.CredentialEvent{Type: model.CredentialAdded, ClusterID: "member-1"}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(fakeStore.stored()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	puts := fakeStore.stored()
+	if len(puts) != 1 {
+		t.Fatalf("expected the event-driven collection to be published once, got %d puts", len(puts))
+	}
+	var ms model.MetricStatus
+	if err := json.Unmarshal(puts[0], &ms); err != nil {
+		t.Fatalf("invalid MetricStatus JSON stored in KV: %v", err)
+	}
+	if len(ms.MemberClusterStatus) != 1 || ms.MemberClusterStatus[0].ClusterId != "member-1" {
+		t.Fatalf("expected the added cluster in the published snapshot, got %+v", ms.MemberClusterStatus)
+	}
+	// 노드별 상태는 KV 에 게시하지 않고 REST API 용 스냅샷에만 남는다.
+	if ms.HostClusterStatus.Nodes != nil || ms.MemberClusterStatus[0].Nodes != nil {
+		t.Fatalf("expected per-node status to be left out of the KV payload, got %+v", ms)
+	}
+	if latest, ok := LatestSnapshot(); !ok || len(latest.MemberClusterStatus) != 1 || len(latest.MemberClusterStatus[0].Nodes) != 1 {
+		t.Fatalf("expected the latest snapshot to be updated with per-node status, got %+v", latest)
+	}
+}
+
+// stubCollectors 는 수집 훅을 정상 응답하는 가짜 함수로 교체하고 복원 함수를 반환한다.
+func stubCollectors() func() {
+	oldKube := NewKubeClient
+	oldNodes := GetNodeListFunc
+	oldNodeMetrics := GetNodeMetricsFunc
+	oldPods := ListPodsFunc
+	oldCollect := CollectMetricFunc
+	oldCollectReq := CollectRequestMetricFunc
+	oldHealth := NodeHealthCheckFunc
+	oldSummary := NodeSummaryFunc
+	oldNodeStatus := CollectNodeStatusFunc
+	oldNamespaces := CollectNamespaceFunc
+	oldClients := clients
+	oldRetry := clusterRetry
+
+	clients = newClientRegistry()
+	clusterRetry = newClusterBackoff()
+	NewKubeClient = func(cfg *rest.Config) (kubernetes.Interface, error) { return nil, nil }
+	GetNodeListFunc = func(ctx context.Context, client kubernetes.Interface) (model.NodeModel, error) {
+		return model.NodeModel{}, nil
+	}
+	GetNodeMetricsFunc = func(ctx context.Context, client kubernetes.Interface) (model.NodeMetricModel, error) {
+		return model.NodeMetricModel{}, nil
+	}
+	ListPodsFunc = func(ctx context.Context, client kubernetes.Interface, node model.NodeModel) ([]corev1.Pod, error) {
+		return nil, nil
+	}
+	CollectMetricFunc = func(node model.NodeModel, nodeMetric model.NodeMetricModel) (float64, float64, error) {
+		return 10.0, 20.0, nil
+	}
+	CollectRequestMetricFunc = func(node model.NodeModel, pods []corev1.Pod) (float64, float64, error) {
+		return 30.0, 40.0, nil
+	}
+	NodeHealthCheckFunc = func(ctx context.Context, client kubernetes.Interface) string { return "True" }
+	NodeSummaryFunc = func(node model.NodeModel) (int, int) { return 1, 1 }
+	CollectNodeStatusFunc = func(node model.NodeModel, nodeMetric model.NodeMetricModel) ([]model.NodeStatus, error) {
+		return []model.NodeStatus{{NodeName: "node-1", Ready: "True"}}, nil
+	}
+	CollectNamespaceFunc = func(ctx context.Context, client kubernetes.Interface, pods []corev1.Pod) ([]model.NamespaceUsage, error) {
+		return []model.NamespaceUsage{{Namespace: "default", Pods: 1}}, nil
+	}
+
+	return func() {
+		// 시간 초과된 수집 고루틴이 훅을 다 쓴 뒤에 되돌린다.
+		inflight.Wait()
+		NewKubeClient = oldKube
+		GetNodeListFunc = oldNodes
+		GetNodeMetricsFunc = oldNodeMetrics
+		ListPodsFunc = oldPods
+		CollectMetricFunc = oldCollect
+		CollectRequestMetricFunc = oldCollectReq
+		NodeHealthCheckFunc = oldHealth
+		NodeSummaryFunc = oldSummary
+		CollectNodeStatusFunc = oldNodeStatus
+		CollectNamespaceFunc = oldNamespaces
+		clients = oldClients
+		clusterRetry = oldRetry
+	}
+}
+
+func TestCollectMembers_SortsAndMarksTimeout(t *testing.T) {
+	restore := stubCollectors()
+	oldTimeout := collectTimeout
+	oldWorkers := collectWorkers
+
+	cancelled := make(chan struct{})
+	defer func() {
+		restore()
+		collectTimeout = oldTimeout
+		collectWorkers = oldWorkers
+	}()
+
+	collectTimeout = 100 * time.Millisecond
+	collectWorkers = 2
+
+	slow := fake.NewClientset()
+	NewKubeClient = func(cfg *rest.Config) (kubernetes.Interface, error) {
+		if cfg.Host == "https://slow" {
+			return slow, nil
+		}
+		return fake.NewClientset(), nil
+	}
+	ListPodsFunc = func(ctx context.Context, client kubernetes.Interface, node model.NodeModel) ([]corev1.Pod, error) {
+		if client == slow {
+			// 응답하지 않는 API 서버는 수집 시간이 초과되어 ctx 가 취소될 때까지 붙잡혀 있다.
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		}
+		return nil, nil
+	}
+
+	got := collectMembers(context.Background(), []collectTarget{
+		{cred: model.ClusterCredential{ClusterID: "member-c", APIServerURL: "https://c"}},
+		{cred: model.ClusterCredential{ClusterID: "member-a", APIServerURL: "https://slow"}},
+		{cred: model.ClusterCredential{ClusterID: "member-b", APIServerURL: "https://b"}},
+	})
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 member clusters, got %d", len(got))
+	}
+	for i, id := range []string{"member-a", "member-b", "member-c"} {
+		if got[i].ClusterId != id {
+			t.Fatalf("index %d: expected %q, got %q", i, id, got[i].ClusterId)
+		}
+	}
+	if got[0].CollectState != model.CollectStateTimeout {
+		t.Fatalf("expected timeout state for slow cluster, got %q", got[0].CollectState)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the API call of the timed-out cluster to be cancelled")
+	}
+	if got[1].CollectState != model.CollectStateOk || got[1].RealTimeUsage.Cpu != 10.0 {
+		t.Fatalf("unexpected member-b status: %+v", got[1])
+	}
+}
+
+func TestClassifyMetricsError(t *testing.T) {
+	gr := schema.GroupResource{Group: "metrics.k8s.io", Resource: "nodes"}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, model.CollectStateOk},
+		{"unauthorized", apierrors.NewUnauthorized("no"), model.CollectStateUnauthorized},
+		{"forbidden", apierrors.NewForbidden(gr, "", errors.New("no")), model.CollectStateUnauthorized},
+		{"metrics api not found", apierrors.NewNotFound(gr, ""), model.CollectStateMetricsAPIMissing},
+		{"metrics api unavailable", apierrors.NewServiceUnavailable("no"), model.CollectStateMetricsAPIMissing},
+		{"network", &url.Error{Op: "Get", URL: "https://x", Err: errors.New("refused")}, model.CollectStateUnreachable},
+		{"other", errors.New("boom"), model.CollectStateDegraded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyMetricsError(tt.err); got != tt.want {
+				t.Fatalf("classifyMetricsError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectCluster_SharesNodeMetricsAcrossCollectors(t *testing.T) {
+	restore := stubCollectors()
+	defer restore()
+
+	fetched := 0
+	GetNodeMetricsFunc = func(ctx context.Context, client kubernetes.Interface) (model.NodeMetricModel, error) {
+		fetched++
+		return model.NodeMetricModel{Items: []model.NodeItem{{NodeInfo: model.MetricMetadata{Name: "node-1"}}}}, nil
+	}
+	var forUsage, forNodes model.NodeMetricModel
+	CollectMetricFunc = func(node model.NodeModel, nodeMetric model.NodeMetricModel) (float64, float64, error) {
+		forUsage = nodeMetric
+		return 10.0, 20.0, nil
+	}
+	CollectNodeStatusFunc = func(node model.NodeModel, nodeMetric model.NodeMetricModel) ([]model.NodeStatus, error) {
+		forNodes = nodeMetric
+		return nil, nil
+	}
+
+	got := collectCluster(context.Background(), collectTarget{cred: model.ClusterCredential{ClusterID: "m1"}})
+	if got.CollectState != model.CollectStateOk {
+		t.Fatalf("unexpected status: %+v", got)
+	}
+	if fetched != 1 || len(forUsage.Items) != 1 || len(forNodes.Items) != 1 {
+		t.Fatalf("expected node metrics fetched once and shared, fetched %d times, got %+v and %+v", fetched, forUsage, forNodes)
+	}
+}
+
+func TestCollectCluster_SharesPodListAcrossCollectors(t *testing.T) {
+	restore := stubCollectors()
+	defer restore()
+
+	pods := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"}}}
+	listed := 0
+	ListPodsFunc = func(ctx context.Context, client kubernetes.Interface, node model.NodeModel) ([]corev1.Pod, error) {
+		listed++
+		return pods, nil
+	}
+	var forRequests, forNamespaces []corev1.Pod
+	CollectRequestMetricFunc = func(node model.NodeModel, pods []corev1.Pod) (float64, float64, error) {
+		forRequests = pods
+		return 30.0, 40.0, nil
+	}
+	CollectNamespaceFunc = func(ctx context.Context, client kubernetes.Interface, pods []corev1.Pod) ([]model.NamespaceUsage, error) {
+		forNamespaces = pods
+		return []model.NamespaceUsage{}, nil
+	}
+
+	got := collectCluster(context.Background(), collectTarget{cred: model.ClusterCredential{ClusterID: "m1"}})
+	if got.CollectState != model.CollectStateOk {
+		t.Fatalf("unexpected status: %+v", got)
+	}
+	if listed != 1 || len(forRequests) != 1 || len(forNamespaces) != 1 {
+		t.Fatalf("expected one pod list shared by both collectors, listed %d times, got %v and %v", listed, forRequests, forNamespaces)
+	}
+
+	ListPodsFunc = func(ctx context.Context, client kubernetes.Interface, node model.NodeModel) ([]corev1.Pod, error) {
+		return nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("rbac"))
+	}
+	forRequests = nil
+	got = collectCluster(context.Background(), collectTarget{cred: model.ClusterCredential{ClusterID: "m1"}})
+	if got.CollectState != model.CollectStateUnauthorized || got.RequestUsageCollected || forRequests != nil {
+		t.Fatalf("expected a pod list failure to skip request usage, got %+v", got)
+	}
+}
+
+func TestCollectCluster_ReportsFailuresAndLastSuccess(t *testing.T) {
+	restore := stubCollectors()
+	oldTracker := lastSuccess
+	defer func() {
+		restore()
+		lastSuccess = oldTracker
+	}()
+	lastSuccess = newSuccessTracker()
+
+	NewKubeClient = func(cfg *rest.Config) (kubernetes.Interface, error) {
+		return nil, errors.New("bad config")
+	}
+	got := collectCluster(context.Background(), collectTarget{cred: model.ClusterCredential{ClusterID: "m1"}})
+	if got.ClusterId != "m1" || got.CollectState != model.CollectStateUnreachable || got.CollectError == "" {
+		t.Fatalf("expected unreachable status with error, got %+v", got)
+	}
+	if got.LastSuccessTime != nil {
+		t.Fatalf("expected no last success time, got %v", got.LastSuccessTime)
+	}
+
+	NewKubeClient = func(cfg *rest.Config) (kubernetes.Interface, error) { return nil, nil }
+	CollectMetricFunc = func(node model.NodeModel, nodeMetric model.NodeMetricModel) (float64, float64, error) {
+		return 12.345, 50, nil
+	}
+	got = collectCluster(context.Background(), collectTarget{cred: model.ClusterCredential{ClusterID: "m1"}})
+	if got.CollectState != model.CollectStateOk || got.RealTimeUsage.Cpu != 12.35 ||
+		!got.RealTimeUsageCollected || !got.RequestUsageCollected {
+		t.Fatalf("unexpected ok status: %+v", got)
+	}
+	if len(got.Nodes) != 1 || got.Nodes[0].NodeName != "node-1" {
+		t.Fatalf("expected per-node status in the cluster status, got %+v", got.Nodes)
+	}
+	if got.NamespaceCount != 1 || len(got.Namespaces) != 1 || got.Namespaces[0].Namespace != "default" {
+		t.Fatalf("expected namespace usage in the cluster status, got %+v", got.Namespaces)
+	}
+	if got.LastSuccessTime == nil {
+		t.Fatalf("expected last success time to be set")
+	}
+	success := *got.LastSuccessTime
+
+	GetNodeMetricsFunc = func(ctx context.Context, client kubernetes.Interface) (model.NodeMetricModel, error) {
+		return model.NodeMetricModel{}, apierrors.NewUnauthorized("expired token")
+	}
+	got = collectCluster(context.Background(), collectTarget{cred: model.ClusterCredential{ClusterID: "m1"}})
+	if got.CollectState != model.CollectStateUnauthorized {
+		t.Fatalf("expected unauthorized, got %q", got.CollectState)
+	}
+	if got.RealTimeUsage.Cpu != 0 || got.RealTimeUsage.Memory != 0 || got.RealTimeUsageCollected {
+		t.Fatalf("expected no usage on failure, got %+v", got.RealTimeUsage)
+	}
+	if !got.RequestUsageCollected {
+		t.Fatalf("expected request usage to stay collected when only metrics fail, got %+v", got)
+	}
+	if got.LastSuccessTime == nil || !got.LastSuccessTime.Equal(success) {
+		t.Fatalf("expected last success time %v to be kept, got %v", success, got.LastSuccessTime)
+	}
+}
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: edge
+  cluster:
+    server: https://edge.example:6443
+    insecure-skip-tls-verify: true
+users:
+- name: edge-user
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: edge-credential
+      interactiveMode: Never
+contexts:
+- name: edge
+  context:
+    cluster: edge
+    user: edge-user
+current-context: edge
+`
+
+func mustRestConfig(t *testing.T, target collectTarget) *rest.Config {
+	t.Helper()
+	cfg, err := restConfigFor(target)
+	if err != nil {
+		t.Fatalf("restConfigFor returned error: %v", err)
+	}
+	return cfg
+}
+
+func TestRestConfigFor_TLS(t *testing.T) {
+	cfg := mustRestConfig(t, collectTarget{cred: model.ClusterCredential{APIServerURL: "https://a", CACert: "pem"}})
+	if cfg.Insecure || string(cfg.CAData) != "pem" {
+		t.Fatalf("expected CA verification, got %+v", cfg.TLSClientConfig)
+	}
+
+	cfg = mustRestConfig(t, collectTarget{cred: model.ClusterCredential{APIServerURL: "https://b"}})
+	if cfg.Insecure || len(cfg.CAData) != 0 {
+		t.Fatalf("expected system roots verification, got %+v", cfg.TLSClientConfig)
+	}
+
+	cfg = mustRestConfig(t, collectTarget{cred: model.ClusterCredential{APIServerURL: "https://c", CACert: "pem", Insecure: true}})
+	if !cfg.Insecure || len(cfg.CAData) != 0 {
+		t.Fatalf("expected insecure opt-in without CA data, got %+v", cfg.TLSClientConfig)
+	}
+
+	restore := stubCollectors()
+	defer restore()
+	got := collectCluster(context.Background(), collectTarget{cred: model.ClusterCredential{ClusterID: "c", Insecure: true}})
+	if !got.InsecureTLS {
+		t.Fatalf("expected insecure TLS to be reported in status")
+	}
+}
+
+func TestRestConfigFor_Auth(t *testing.T) {
+	cfg := mustRestConfig(t, collectTarget{cred: model.ClusterCredential{APIServerURL: "https://a", BearerToken: "token"}})
+	if cfg.BearerToken != "token" || len(cfg.CertData) != 0 {
+		t.Fatalf("expected bearer token auth, got %+v", cfg)
+	}
+
+	cfg = mustRestConfig(t, collectTarget{cred: model.ClusterCredential{APIServerURL: "https://b", ClientCert: "cert", ClientKey: "key"}})
+	if cfg.BearerToken != "" || string(cfg.CertData) != "cert" || string(cfg.KeyData) != "key" {
+		t.Fatalf("expected client certificate auth, got %+v", cfg)
+	}
+
+	cfg = mustRestConfig(t, collectTarget{cred: model.ClusterCredential{APIServerURL: "https://ignored", BearerToken: "token", Kubeconfig: testKubeconfig}})
+	if cfg.Host != "https://edge.example:6443" || cfg.ExecProvider == nil || cfg.ExecProvider.Command != "edge-credential" {
+		t.Fatalf("expected kubeconfig exec auth, got host=%q exec=%+v", cfg.Host, cfg.ExecProvider)
+	}
+	if cfg.Timeout != collectTimeout {
+		t.Fatalf("expected collect timeout on kubeconfig config, got %s", cfg.Timeout)
+	}
+
+	if _, err := restConfigFor(collectTarget{cred: model.ClusterCredential{Kubeconfig: "::not yaml"}}); err == nil {
+		t.Fatalf("expected error for invalid kubeconfig")
+	}
+}
+
+func TestParseTopN(t *testing.T) {
+	for value, want := range map[string]int{"": 10, " 25 ": 25, "0": 0, "-1": -1, "ten": 10} {
+		if got := parseTopN("NamespaceTopN", value, 10); got != want {
+			t.Fatalf("parseTopN(%q) = %d, want %d", value, got, want)
+		}
+	}
 }
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"federation-metric-api/internal/karmada"
+	"federation-metric-api/internal/metricscollector"
+	"federation-metric-api/model"
+)
+
+// bindingLister 는 ResourceBinding 을 조회할 수 있는 Karmada 클라이언트이다.
+type bindingLister interface {
+	GetResourceBindings(ctx context.Context) ([]karmada.ResourceBinding, error)
+}
+
+// workloadKinds 는 파드를 만들어 사용량을 집계할 수 있는 ResourceBinding 대상 종류이다.
+var workloadKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+	"Job":         true,
+}
+
+type workloadRef struct {
+	namespace, kind, name string
+}
+
+// workloadTracker 는 마지막 수집에서 얻은 클러스터별 워크로드 사용량이다. 키는 Karmada 멤버 이름이며
+// Karmada 멤버로 수집하지 않은 호스트 클러스터는 ClusterID 를 사용한다.
+type workloadTracker struct {
+	mu        sync.Mutex
+	byCluster map[string]clusterWorkloads
+}
+
+// clusterWorkloads 는 클러스터 하나의 워크로드 사용량이다. usageKnown 은 파드 지표를 모두 수집했는지이며,
+// false 이면 목록에 없는 워크로드의 사용량을 0 이 아닌 모르는 값으로 둔다.
+type clusterWorkloads struct {
+	workloads  map[workloadRef]model.WorkloadUsage
+	usageKnown bool
+}
+
+func newWorkloadTracker() *workloadTracker {
+	return &workloadTracker{byCluster: make(map[string]clusterWorkloads)}
+}
+
+var workloadUsage = newWorkloadTracker()
+
+func usageKey(target collectTarget) string {
+	if target.karmadaName != "" {
+		return target.karmadaName
+	}
+	return target.cred.ClusterID
+}
+
+// record 는 클러스터의 워크로드 사용량을 바꾼다. namespaces 가 nil 이면 사용량을 모르는 상태로 지운다.
+// usageKnown 은 파드 지표 조회가 성공해 모든 Running 파드의 실사용량이 반영되었는지이다.
+func (t *workloadTracker) record(cluster string, namespaces []model.NamespaceUsage, usageKnown bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if namespaces == nil {
+		delete(t.byCluster, cluster)
+		return
+	}
+	workloads := make(map[workloadRef]model.WorkloadUsage)
+	for _, ns := range namespaces {
+		for _, w := range ns.Workloads {
+			workloads[workloadRef{namespace: ns.Namespace, kind: w.Kind, name: w.Name}] = w
+		}
+	}
+	t.byCluster[cluster] = clusterWorkloads{workloads: workloads, usageKnown: usageKnown}
+}
+
+func (t *workloadTracker) retain(targets []collectTarget) {
+	keep := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		keep[usageKey(target)] = true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for cluster := range t.byCluster {
+		if !keep[cluster] {
+			delete(t.byCluster, cluster)
+		}
+	}
+}
+
+// federatedWorkloads 는 워크로드 ResourceBinding 마다 멤버 클러스터별 배치 레플리카와 마지막으로 수집한
+// 파드 사용량을 합친다. 결과는 네임스페이스, 종류, 이름 순이다.
+func (t *workloadTracker) federatedWorkloads(bindings []karmada.ResourceBinding) []model.FederatedWorkload {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]model.FederatedWorkload, 0)
+	for _, binding := range bindings {
+		res := binding.Resource
+		if !workloadKinds[res.Kind] {
+			continue
+		}
+		ref := workloadRef{namespace: res.Namespace, kind: res.Kind, name: res.Name}
+		workload := model.FederatedWorkload{
+			Kind:       res.Kind,
+			Namespace:  res.Namespace,
+			Name:       res.Name,
+			Binding:    binding.Name,
+			Replicas:   binding.Replicas,
+			Placements: make([]model.WorkloadPlacement, 0, len(binding.Clusters)),
+		}
+		for _, target := range binding.Clusters {
+			placement := model.WorkloadPlacement{Cluster: target.Name, Replicas: target.Replicas}
+			if collected, ok := t.byCluster[target.Name]; ok {
+				usage, found := collected.workloads[ref]
+				placement.Pods = usage.Pods
+				placement.Requests = usage.Requests
+				placement.Usage = usage.Usage
+				if !found && collected.usageKnown {
+					// 파드 지표를 수집한 클러스터에 워크로드 파드가 없으면 사용량은 0 이다.
+					placement.Usage = &model.NodeResources{}
+				}
+
+				workload.Requests.Cpu += placement.Requests.Cpu
+				workload.Requests.Memory += placement.Requests.Memory
+				if placement.Usage != nil {
+					if workload.Usage == nil {
+						workload.Usage = &model.NodeResources{}
+					}
+					workload.Usage.Cpu += placement.Usage.Cpu
+					workload.Usage.Memory += placement.Usage.Memory
+				}
+			}
+			workload.Placements = append(workload.Placements, placement)
+		}
+		sort.Slice(workload.Placements, func(i, j int) bool {
+			return workload.Placements[i].Cluster < workload.Placements[j].Cluster
+		})
+		result = append(result, workload)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return result
+}
+
+// topWorkloads 는 사용량 상위 n 개 워크로드만 남긴다. 결과는 federatedWorkloads 와 같이 네임스페이스,
+// 종류, 이름 순이다. n 이 0 이하이면 자르지 않는다.
+func topWorkloads(workloads []model.FederatedWorkload, n int) []model.FederatedWorkload {
+	if n <= 0 || len(workloads) <= n {
+		return workloads
+	}
+	top := append([]model.FederatedWorkload(nil), workloads...)
+	sort.SliceStable(top, func(i, j int) bool {
+		a, b := top[i], top[j]
+		return metricscollector.Heavier(a.Usage, a.Requests, a.Namespace+"/"+a.Kind+"/"+a.Name,
+			b.Usage, b.Requests, b.Namespace+"/"+b.Kind+"/"+b.Name)
+	})
+	top = top[:n]
+	sort.Slice(top, func(i, j int) bool {
+		a, b := top[i], top[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return top
+}
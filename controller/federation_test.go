@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"federation-metric-api/internal/karmada"
+	"federation-metric-api/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+type bindingKarm struct {
+	fakeKarm
+	bindings []karmada.ResourceBinding
+	err      error
+}
+
+func (f *bindingKarm) GetResourceBindings(ctx context.Context) ([]karmada.ResourceBinding, error) {
+	return f.bindings, f.err
+}
+
+func TestFederatedWorkloads_CombinesPlacementWithMemberUsage(t *testing.T) {
+	tracker := newWorkloadTracker()
+	tracker.record("member-1", []model.NamespaceUsage{{
+		Namespace: "shop",
+		Workloads: []model.WorkloadUsage{
+			{Kind: "Deployment", Name: "web", Pods: 2, Usage: &model.NodeResources{Cpu: 0.4, Memory: 200}, Requests: model.NodeResources{Cpu: 0.5, Memory: 512}},
+		},
+	}}, true)
+	// member-2 는 수집되었지만 워크로드 파드가 아직 없고, member-3 은 수집에 실패했다.
+	tracker.record("member-2", []model.NamespaceUsage{}, true)
+	tracker.record("member-3", nil, false)
+	// member-4, member-5 는 파드 목록은 수집했지만 파드 지표 조회에 실패했다.
+	tracker.record("member-4", []model.NamespaceUsage{{
+		Namespace: "shop",
+		Workloads: []model.WorkloadUsage{
+			{Kind: "Deployment", Name: "web", Pods: 1, Requests: model.NodeResources{Cpu: 0.25, Memory: 256}},
+		},
+	}}, false)
+	tracker.record("member-5", []model.NamespaceUsage{}, false)
+
+	bindings := []karmada.ResourceBinding{
+		{
+			Namespace: "shop", Name: "web-deployment", Replicas: 4,
+			Resource: karmada.ObjectReference{Kind: "Deployment", Namespace: "shop", Name: "web"},
+			Clusters: []karmada.TargetCluster{
+				{Name: "member-3", Replicas: 1}, {Name: "member-2", Replicas: 1}, {Name: "member-1", Replicas: 2},
+				{Name: "member-4", Replicas: 1}, {Name: "member-5", Replicas: 1},
+			},
+		},
+		{
+			Namespace: "shop", Name: "web-service",
+			Resource: karmada.ObjectReference{Kind: "Service", Namespace: "shop", Name: "web"},
+			Clusters: []karmada.TargetCluster{{Name: "member-1"}},
+		},
+	}
+
+	got := tracker.federatedWorkloads(bindings)
+	if len(got) != 1 {
+		t.Fatalf("expected only the Deployment binding, got %+v", got)
+	}
+	web := got[0]
+	if web.Kind != "Deployment" || web.Name != "web" || web.Binding != "web-deployment" || web.Replicas != 4 {
+		t.Fatalf("unexpected workload: %+v", web)
+	}
+	if web.Usage == nil || web.Usage.Cpu != 0.4 || web.Requests.Memory != 768 {
+		t.Fatalf("unexpected workload totals: %+v %+v", web.Usage, web.Requests)
+	}
+	if len(web.Placements) != 5 || web.Placements[0].Cluster != "member-1" {
+		t.Fatalf("expected placements sorted by cluster, got %+v", web.Placements)
+	}
+	if p := web.Placements[0]; p.Replicas != 2 || p.Pods != 2 || p.Usage.Cpu != 0.4 {
+		t.Fatalf("unexpected member-1 placement: %+v", p)
+	}
+	if p := web.Placements[1]; p.Usage == nil || p.Usage.Cpu != 0 || p.Pods != 0 {
+		t.Fatalf("expected zero usage for a collected member without pods, got %+v", p)
+	}
+	if p := web.Placements[2]; p.Usage != nil || p.Replicas != 1 {
+		t.Fatalf("expected unknown usage for a member that failed collection, got %+v", p)
+	}
+	if p := web.Placements[3]; p.Usage != nil || p.Pods != 1 || p.Requests.Cpu != 0.25 {
+		t.Fatalf("expected unknown usage with requests for a member without pod metrics, got %+v", p)
+	}
+	if p := web.Placements[4]; p.Usage != nil || p.Pods != 0 {
+		t.Fatalf("expected unknown usage for a member without pod metrics or pods, got %+v", p)
+	}
+
+	tracker.retain([]collectTarget{{cred: model.ClusterCredential{ClusterID: "m2"}, karmadaName: "member-2"}})
+	if got := tracker.federatedWorkloads(bindings); got[0].Placements[0].Usage != nil {
+		t.Fatalf("expected usage of removed clusters to be dropped, got %+v", got[0].Placements[0])
+	}
+}
+
+func TestTopWorkloads_KeepsHeaviestInNameOrder(t *testing.T) {
+	workloads := []model.FederatedWorkload{
+		{Kind: "Deployment", Namespace: "a", Name: "idle", Usage: &model.NodeResources{Cpu: 1}},
+		{Kind: "Deployment", Namespace: "a", Name: "unknown"},
+		{Kind: "Deployment", Namespace: "b", Name: "busy", Usage: &model.NodeResources{Cpu: 500}},
+		{Kind: "StatefulSet", Namespace: "c", Name: "db", Usage: &model.NodeResources{Cpu: 200}},
+	}
+
+	top := topWorkloads(workloads, 2)
+	if len(top) != 2 || top[0].Name != "busy" || top[1].Name != "db" {
+		t.Fatalf("expected the two heaviest workloads in name order, got %+v", top)
+	}
+	if len(workloads) != 4 || workloads[0].Name != "idle" {
+		t.Fatalf("expected the input to be left untouched, got %+v", workloads)
+	}
+	if all := topWorkloads(workloads, 0); len(all) != 4 {
+		t.Fatalf("expected no cut-off for n <= 0, got %d workloads", len(all))
+	}
+}
+
+func TestCollectCluster_TimedOutCollectionDoesNotRecordWorkloads(t *testing.T) {
+	restore := stubCollectors()
+	oldTimeout := collectTimeout
+	oldUsage := workloadUsage
+	defer func() {
+		restore()
+		collectTimeout = oldTimeout
+		workloadUsage = oldUsage
+	}()
+
+	collectTimeout = 100 * time.Millisecond
+	workloadUsage = newWorkloadTracker()
+	workloadUsage.record("member-1", []model.NamespaceUsage{}, true)
+	NewKubeClient = func(cfg *rest.Config) (kubernetes.Interface, error) { return fake.NewClientset(), nil }
+	// 네임스페이스 사용량 조회가 시간 초과 뒤에야 결과를 돌려준다.
+	CollectNamespaceFunc = func(ctx context.Context, client kubernetes.Interface, pods []corev1.Pod) ([]model.NamespaceUsage, error) {
+		<-ctx.Done()
+		return []model.NamespaceUsage{{Namespace: "shop"}}, nil
+	}
+
+	target := collectTarget{cred: model.ClusterCredential{ClusterID: "m1", APIServerURL: "https://m1"}, karmadaName: "member-1"}
+	if got := collectCluster(context.Background(), target); got.CollectState != model.CollectStateTimeout {
+		t.Fatalf("expected timeout, got %+v", got)
+	}
+	inflight.Wait()
+	if _, ok := workloadUsage.byCluster["member-1"]; ok {
+		t.Fatalf("expected workload usage of a timed-out collection to be unknown, got %+v", workloadUsage.byCluster)
+	}
+}
+
+func TestRepeatMetric_BindingFailureDoesNotFailKarmada(t *testing.T) {
+	restore := stubCollectors()
+	oldRepeat := repeatTime
+	oldKarm := NewKarmadaClient
+	oldNats := NewNatsClient
+	oldGet := GetClusterInfos
+	oldDeps := dependencies
+	oldLoop := loop
+	defer func() {
+		restore()
+		repeatTime = oldRepeat
+		NewKarmadaClient = oldKarm
+		NewNatsClient = oldNats
+		GetClusterInfos = oldGet
+		dependencies = oldDeps
+		loop = oldLoop
+	}()
+
+	repeatTime = 1
+	dependencies = newDependencyTracker(DependencyCredentials, DependencyKarmada, DependencyNats)
+	loop = &loopState{heartbeat: time.Now()}
+	NewKarmadaClient = func() KarmadaClient {
+		return &bindingKarm{err: errors.New("resourcebindings is forbidden")}
+	}
+	NewNatsClient = func() NatsClient { return &fakeNats{kv: &fakeKV{}} }
+	GetClusterInfos = fakeGetClusterInfos
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RepeatMetric(ctx)
+	}()
+	time.Sleep(1500 * time.Millisecond)
+	cancel()
+	<-done
+
+	byName := map[string]model.DependencyStatus{}
+	for _, d := range Dependencies() {
+		byName[d.Name] = d
+	}
+	if k := byName[DependencyKarmada]; !k.Healthy {
+		t.Fatalf("expected Karmada healthy once members are fetched, got %+v", k)
+	}
+	if b := byName[DependencyBindings]; b.Healthy || b.Error != "resourcebindings is forbidden" {
+		t.Fatalf("expected binding failure tracked separately, got %+v", b)
+	}
+	if h := Readiness(); h.Status != model.HealthUp {
+		t.Fatalf("expected binding failure not to gate readiness, got %+v", h)
+	}
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"federation-metric-api/model"
+)
+
+// healthIntervals 는 스냅샷 게시와 수집 루프 진행이 몇 주기 동안 없으면 비정상으로 볼지 정한다.
+var healthIntervals = 3
+
+// loopState 는 헬스 체크가 참조하는 수집 루프의 진행 기록이다.
+type loopState struct {
+	mu            sync.Mutex
+	heartbeat     time.Time
+	lastPublished time.Time
+}
+
+// 프로세스 시작 직후에는 루프가 아직 돌지 않았으므로 시작 시각을 첫 heartbeat 로 둔다.
+var loop = &loopState{heartbeat: time.Now()}
+
+// beat 는 수집 루프가 진행 중임을 기록한다. 루프 반복과 클러스터 하나의 수집이 끝날 때마다 호출된다.
+func (l *loopState) beat(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.heartbeat = now
+}
+
+func (l *loopState) published(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.heartbeat = now
+	l.lastPublished = now
+}
+
+func (l *loopState) times() (heartbeat, lastPublished time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.heartbeat, l.lastPublished
+}
+
+func cycle() time.Duration {
+	return repeatTime * time.Second
+}
+
+// Liveness 는 수집 루프가 멈추지 않았는지 확인한다. 클러스터 하나의 수집은 collectTimeout 안에
+// 끝나므로 healthIntervals 주기와 collectTimeout 이 지나도록 진행이 없으면 멈춘 것으로 본다.
+func Liveness() model.Health {
+	return livenessAt(time.Now())
+}
+
+func livenessAt(now time.Time) model.Health {
+	heartbeat, _ := loop.times()
+	maxStall := time.Duration(healthIntervals)*cycle() + collectTimeout
+
+	collector := model.HealthComponent{
+		Status: model.HealthUp,
+		Details: map[string]interface{}{
+			"lastHeartbeat": heartbeat.UTC(),
+			"maxStall":      maxStall.String(),
+		},
+	}
+	if now.Sub(heartbeat) > maxStall {
+		collector.Status = model.HealthDown
+	}
+	return aggregate(map[string]model.HealthComponent{"collector": collector})
+}
+
+// Readiness 는 NATS KV 게시 가능 여부, 클러스터 인증 정보와 Karmada 멤버 목록 적재 여부,
+// healthIntervals 주기 안에 스냅샷을 게시했는지를 확인한다.
+func Readiness() model.Health {
+	return readinessAt(time.Now())
+}
+
+func readinessAt(now time.Time) model.Health {
+	components := make(map[string]model.HealthComponent)
+	for _, dep := range dependencies.statuses() {
+		if dep.Name == DependencyBindings {
+			// 바인딩은 워크로드 보강 정보일 뿐이라 조회 권한이 없어도 수집과 게시는 정상이다.
+			continue
+		}
+		up := dep.LastSuccessTime != nil
+		if dep.Name == DependencyNats {
+			// 게시 대상이므로 마지막 호출이 성공한 상태여야 한다.
+			up = dep.Healthy
+		}
+		components[dep.Name] = dependencyComponent(dep, up)
+	}
+
+	_, lastPublished := loop.times()
+	maxAge := time.Duration(healthIntervals) * cycle()
+	snapshot := model.HealthComponent{
+		Status:  model.HealthDown,
+		Details: map[string]interface{}{"maxAge": maxAge.String()},
+	}
+	if !lastPublished.IsZero() {
+		snapshot.Details["lastPublished"] = lastPublished.UTC()
+		if now.Sub(lastPublished) <= maxAge {
+			snapshot.Status = model.HealthUp
+		}
+	}
+	components["snapshot"] = snapshot
+
+	return aggregate(components)
+}
+
+func dependencyComponent(dep model.DependencyStatus, up bool) model.HealthComponent {
+	c := model.HealthComponent{Status: model.HealthDown, Details: map[string]interface{}{}}
+	if up {
+		c.Status = model.HealthUp
+	}
+	if dep.LastSuccessTime != nil {
+		c.Details["lastSuccessTime"] = *dep.LastSuccessTime
+	}
+	if dep.Error != "" {
+		c.Details["error"] = dep.Error
+		c.Details["consecutiveFailures"] = dep.ConsecutiveFailures
+		if up {
+			// 일부만 실패했거나 마지막으로 받은 데이터로 계속 수집 중인 상태이다.
+			c.Details["degraded"] = true
+		}
+	}
+	if dep.NextRetryTime != nil {
+		c.Details["nextRetryTime"] = *dep.NextRetryTime
+	}
+	return c
+}
+
+func aggregate(components map[string]model.HealthComponent) model.Health {
+	h := model.Health{Status: model.HealthUp, Components: components}
+	for _, c := range components {
+		if c.Status != model.HealthUp {
+			h.Status = model.HealthDown
+		}
+	}
+	return h
+}
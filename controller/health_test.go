@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"federation-metric-api/model"
+)
+
+func TestLiveness_DetectsStalledLoop(t *testing.T) {
+	oldLoop := loop
+	defer func() { loop = oldLoop }()
+
+	now := time.Now()
+	loop = &loopState{heartbeat: now}
+	if h := livenessAt(now.Add(time.Second)); h.Status != model.HealthUp {
+		t.Fatalf("expected liveness UP right after a heartbeat, got %+v", h)
+	}
+
+	maxStall := time.Duration(healthIntervals)*cycle() + collectTimeout
+	h := livenessAt(now.Add(maxStall + time.Second))
+	if h.Status != model.HealthDown || h.Components["collector"].Status != model.HealthDown {
+		t.Fatalf("expected liveness DOWN for a stalled loop, got %+v", h)
+	}
+}
+
+func TestReadiness_RequiresDependenciesAndRecentSnapshot(t *testing.T) {
+	oldLoop, oldDeps := loop, dependencies
+	defer func() { loop, dependencies = oldLoop, oldDeps }()
+
+	now := time.Now()
+	loop = &loopState{heartbeat: now}
+	dependencies = newDependencyTracker(DependencyCredentials, DependencyKarmada, DependencyNats)
+
+	h := readinessAt(now)
+	if h.Status != model.HealthDown {
+		t.Fatalf("expected readiness DOWN before anything loaded, got %+v", h)
+	}
+	for _, name := range []string{DependencyCredentials, DependencyKarmada, DependencyNats, "snapshot"} {
+		if h.Components[name].Status != model.HealthDown {
+			t.Fatalf("expected %s DOWN, got %+v", name, h.Components[name])
+		}
+	}
+
+	dependencies.success(DependencyCredentials, now)
+	dependencies.success(DependencyKarmada, now)
+	dependencies.success(DependencyNats, now)
+	loop.published(now)
+	if h := readinessAt(now); h.Status != model.HealthUp {
+		t.Fatalf("expected readiness UP, got %+v", h)
+	}
+
+	// 인증 정보 갱신 실패는 마지막으로 적재된 정보로 계속 수집하므로 준비 상태를 유지한다.
+	dependencies.failure(DependencyCredentials, errors.New("vault sealed"), now)
+	h = readinessAt(now)
+	if h.Status != model.HealthUp || h.Components[DependencyCredentials].Details["error"] != "vault sealed" {
+		t.Fatalf("expected readiness UP with credential error detail, got %+v", h)
+	}
+
+	// 바인딩 조회 실패는 워크로드 보강 정보만 빠지므로 준비 상태에 영향을 주지 않는다.
+	dependencies.failure(DependencyBindings, errors.New("forbidden"), now)
+	if h := readinessAt(now); h.Status != model.HealthUp {
+		t.Fatalf("expected readiness UP when only bindings fail, got %+v", h)
+	}
+
+	dependencies.failure(DependencyNats, errors.New("connection closed"), now)
+	if h := readinessAt(now); h.Status != model.HealthDown || h.Components[DependencyNats].Status != model.HealthDown {
+		t.Fatalf("expected readiness DOWN when NATS fails, got %+v", h)
+	}
+	dependencies.success(DependencyNats, now)
+
+	stale := now.Add(time.Duration(healthIntervals)*cycle() + time.Second)
+	if h := readinessAt(stale); h.Components["snapshot"].Status != model.HealthDown {
+		t.Fatalf("expected snapshot DOWN after %d missed intervals, got %+v", healthIntervals, h.Components["snapshot"])
+	}
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"federation-metric-api/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Registry 는 /metrics 로 노출되는 Prometheus 레지스트리이다.
+var Registry = prometheus.NewRegistry()
+
+var (
+	cycleDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "federation_collector_cycle_duration_seconds",
+		Help:    "Time taken to collect all clusters in one cycle.",
+		Buckets: prometheus.ExponentialBuckets(0.25, 2, 9),
+	})
+	clusterCollectDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "federation_collector_cluster_collect_duration_seconds",
+		Help:    "Time taken to collect a single cluster.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"cluster"})
+	clusterErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "federation_collector_cluster_errors_total",
+		Help: "Cluster collection failures by collect state.",
+	}, []string{"cluster", "type"})
+	dependencyErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "federation_collector_dependency_errors_total",
+		Help: "Failed calls to the credential source, Karmada and NATS.",
+	}, []string{"dependency"})
+	kvPutFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "federation_collector_kv_put_failures_total",
+		Help: "Failed NATS KV puts of the metric snapshot.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		cycleDuration,
+		clusterCollectDuration,
+		clusterErrors,
+		dependencyErrors,
+		kvPutFailures,
+		snapshotCollector{},
+	)
+}
+
+// 클러스터 지표는 마지막 스냅샷에서 만들어 사라진 클러스터의 시계열이 남지 않도록 한다.
+var (
+	usageDesc = prometheus.NewDesc("federation_cluster_usage_percent",
+		"CPU and memory usage of a cluster in percent, measured (realtime) or requested (request).",
+		[]string{"cluster", "role", "kind", "resource"}, nil)
+	nodesDesc = prometheus.NewDesc("federation_cluster_nodes",
+		"Number of nodes in a cluster by readiness.",
+		[]string{"cluster", "role", "state"}, nil)
+	healthyDesc = prometheus.NewDesc("federation_cluster_healthy",
+		"Whether the cluster API server healthz check returned ok (1) or not (0).",
+		[]string{"cluster", "role"}, nil)
+	upDesc = prometheus.NewDesc("federation_cluster_up",
+		"Whether the last collection of the cluster succeeded (1) or failed (0).",
+		[]string{"cluster", "role", "state"}, nil)
+	snapshotTimeDesc = prometheus.NewDesc("federation_collector_snapshot_timestamp_seconds",
+		"Unix time of the latest metric snapshot.", nil, nil)
+)
+
+type snapshotCollector struct{}
+
+func (snapshotCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- usageDesc
+	ch <- nodesDesc
+	ch <- healthyDesc
+	ch <- upDesc
+	ch <- snapshotTimeDesc
+}
+
+func (snapshotCollector) Collect(ch chan<- prometheus.Metric) {
+	status, ok := latest.get()
+	if !ok {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(snapshotTimeDesc, prometheus.GaugeValue, float64(status.Time.UnixNano())/1e9)
+
+	if status.HostClusterStatus.ClusterId != "" {
+		collectClusterMetrics(ch, status.HostClusterStatus, "host")
+	}
+	for _, member := range status.MemberClusterStatus {
+		collectClusterMetrics(ch, member, "member")
+	}
+}
+
+func collectClusterMetrics(ch chan<- prometheus.Metric, cluster model.ClusterStatus, role string) {
+	id := cluster.ClusterId
+	up := cluster.CollectState == model.CollectStateOk || cluster.CollectState == model.CollectStateDegraded
+	ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, boolValue(up), id, role, cluster.CollectState)
+	ch <- prometheus.MustNewConstMetric(healthyDesc, prometheus.GaugeValue, boolValue(cluster.Status == "True"), id, role)
+	if !up {
+		return
+	}
+	// degraded 클러스터는 수집하지 못한 사용률을 0% 로 내보내지 않도록 얻은 값만 내보낸다.
+	if cluster.RealTimeUsageCollected {
+		ch <- prometheus.MustNewConstMetric(usageDesc, prometheus.GaugeValue, cluster.RealTimeUsage.Cpu, id, role, "realtime", "cpu")
+		ch <- prometheus.MustNewConstMetric(usageDesc, prometheus.GaugeValue, cluster.RealTimeUsage.Memory, id, role, "realtime", "memory")
+	}
+	if cluster.RequestUsageCollected {
+		ch <- prometheus.MustNewConstMetric(usageDesc, prometheus.GaugeValue, cluster.RequestUsage.Cpu, id, role, "request", "cpu")
+		ch <- prometheus.MustNewConstMetric(usageDesc, prometheus.GaugeValue, cluster.RequestUsage.Memory, id, role, "request", "memory")
+	}
+	ch <- prometheus.MustNewConstMetric(nodesDesc, prometheus.GaugeValue, float64(cluster.NodeSummary.TotalNum), id, role, "total")
+	ch <- prometheus.MustNewConstMetric(nodesDesc, prometheus.GaugeValue, float64(cluster.NodeSummary.ReadyNum), id, role, "ready")
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
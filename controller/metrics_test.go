@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"federation-metric-api/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSnapshotCollector_ExportsLatestClusterMetrics(t *testing.T) {
+	oldLatest := latest
+	defer func() { latest = oldLatest }()
+	latest = &snapshotStore{}
+
+	if n := testutil.CollectAndCount(snapshotCollector{}); n != 0 {
+		t.Fatalf("expected no metrics before the first snapshot, got %d", n)
+	}
+
+	latest.set(model.MetricStatus{
+		Time: time.Unix(1700000000, 0),
+		HostClusterStatus: model.ClusterStatus{
+			ClusterId: "host-1", CollectState: model.CollectStateOk, Status: "True",
+			NodeSummary:            model.NodeSummary{TotalNum: 3, ReadyNum: 3},
+			RealTimeUsage:          model.NodeUsageFloat{Cpu: 12.5, Memory: 40},
+			RequestUsage:           model.NodeUsageFloat{Cpu: 30, Memory: 55.5},
+			RealTimeUsageCollected: true, RequestUsageCollected: true,
+		},
+		MemberClusterStatus: []model.ClusterStatus{
+			{ClusterId: "member-1", CollectState: model.CollectStateUnreachable, Status: "Unknown"},
+			// metrics-server 조회에 실패해 요청 사용률만 수집되었다.
+			{
+				ClusterId: "member-2", CollectState: model.CollectStateDegraded, Status: "True",
+				NodeSummary:  model.NodeSummary{TotalNum: 2, ReadyNum: 2},
+				RequestUsage: model.NodeUsageFloat{Cpu: 25, Memory: 35}, RequestUsageCollected: true,
+			},
+		},
+	})
+
+	expected := `
+# HELP federation_cluster_healthy Whether the cluster API server healthz check returned ok (1) or not (0).
+# TYPE federation_cluster_healthy gauge
+federation_cluster_healthy{cluster="host-1",role="host"} 1
+federation_cluster_healthy{cluster="member-1",role="member"} 0
+federation_cluster_healthy{cluster="member-2",role="member"} 1
+# HELP federation_cluster_nodes Number of nodes in a cluster by readiness.
+# TYPE federation_cluster_nodes gauge
+federation_cluster_nodes{cluster="host-1",role="host",state="ready"} 3
+federation_cluster_nodes{cluster="host-1",role="host",state="total"} 3
+federation_cluster_nodes{cluster="member-2",role="member",state="ready"} 2
+federation_cluster_nodes{cluster="member-2",role="member",state="total"} 2
+# HELP federation_cluster_up Whether the last collection of the cluster succeeded (1) or failed (0).
+# TYPE federation_cluster_up gauge
+federation_cluster_up{cluster="host-1",role="host",state="ok"} 1
+federation_cluster_up{cluster="member-1",role="member",state="unreachable"} 0
+federation_cluster_up{cluster="member-2",role="member",state="degraded"} 1
+# HELP federation_cluster_usage_percent CPU and memory usage of a cluster in percent, measured (realtime) or requested (request).
+# TYPE federation_cluster_usage_percent gauge
+federation_cluster_usage_percent{cluster="host-1",kind="realtime",resource="cpu",role="host"} 12.5
+federation_cluster_usage_percent{cluster="host-1",kind="realtime",resource="memory",role="host"} 40
+federation_cluster_usage_percent{cluster="host-1",kind="request",resource="cpu",role="host"} 30
+federation_cluster_usage_percent{cluster="host-1",kind="request",resource="memory",role="host"} 55.5
+federation_cluster_usage_percent{cluster="member-2",kind="request",resource="cpu",role="member"} 25
+federation_cluster_usage_percent{cluster="member-2",kind="request",resource="memory",role="member"} 35
+# HELP federation_collector_snapshot_timestamp_seconds Unix time of the latest metric snapshot.
+# TYPE federation_collector_snapshot_timestamp_seconds gauge
+federation_collector_snapshot_timestamp_seconds 1.7e+09
+`
+	if err := testutil.CollectAndCompare(snapshotCollector{}, strings.NewReader(expected)); err != nil {
+		t.Fatalf("unexpected metrics: %v", err)
+	}
+}
+
+func TestRegistry_GathersCollectorMetrics(t *testing.T) {
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather returned error: %v", err)
+	}
+	names := map[string]bool{}
+	for _, f := range families {
+		names[f.GetName()] = true
+	}
+	for _, want := range []string{"federation_collector_cycle_duration_seconds", "federation_collector_kv_put_failures_total", "go_goroutines"} {
+		if !names[want] {
+			t.Fatalf("expected %s to be registered", want)
+		}
+	}
+}
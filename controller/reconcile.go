@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"federation-metric-api/internal/karmada"
+	"federation-metric-api/model"
+	"sort"
+	"strings"
+)
+
+func normalizeEndpoint(endpoint string) string {
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(endpoint), "/"))
+}
+
+// reconcileClusters 는 Karmada 멤버 클러스터와 인증 정보를 이름, 엔드포인트 순으로 매칭한다.
+// 매칭된 멤버의 인증 정보와 함께 인증 정보가 없는 멤버, Karmada 에 등록되지 않은 인증 정보,
+// 이름은 같지만 엔드포인트가 다른 클러스터를 보고한다. 호스트 클러스터는 매칭 대상에서 제외한다.
+func reconcileClusters(members []karmada.MemberCluster, creds []model.ClusterCredential) ([]model.ClusterCredential, model.Reconciliation) {
+	result := model.Reconciliation{
+		Matched:              []model.ClusterMatch{},
+		MissingCredentials:   []string{},
+		UnregisteredClusters: []string{},
+		EndpointMismatches:   []model.EndpointMismatch{},
+	}
+
+	byName := make(map[string]int)
+	byEndpoint := make(map[string]int)
+	for i, ci := range creds {
+		if ci.ClusterID == hostClusterName {
+			continue
+		}
+		byName[ci.ClusterID] = i
+		byEndpoint[normalizeEndpoint(ci.APIServerURL)] = i
+	}
+
+	used := make(map[int]bool)
+	var matched []model.ClusterCredential
+
+	sorted := append([]karmada.MemberCluster(nil), members...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	// 이름이 일치하는 Karmada 멤버가 있는 인증 정보는 엔드포인트 매칭에 사용하지 않는다.
+	memberNames := make(map[string]bool)
+	for _, member := range sorted {
+		memberNames[member.Name] = true
+	}
+
+	for _, member := range sorted {
+		if member.Name == hostClusterName {
+			continue
+		}
+		if i, ok := byName[member.Name]; ok && !used[i] {
+			used[i] = true
+			ci := creds[i]
+			if normalizeEndpoint(ci.APIServerURL) != normalizeEndpoint(member.Endpoint) {
+				result.EndpointMismatches = append(result.EndpointMismatches, model.EndpointMismatch{
+					ClusterId:          ci.ClusterID,
+					KarmadaEndpoint:    member.Endpoint,
+					CredentialEndpoint: ci.APIServerURL,
+				})
+			}
+			result.Matched = append(result.Matched, model.ClusterMatch{
+				ClusterId:   ci.ClusterID,
+				KarmadaName: member.Name,
+				MatchedBy:   model.MatchedByName,
+			})
+			matched = append(matched, ci)
+			continue
+		}
+		if i, ok := byEndpoint[normalizeEndpoint(member.Endpoint)]; ok && !used[i] && !memberNames[creds[i].ClusterID] {
+			used[i] = true
+			result.Matched = append(result.Matched, model.ClusterMatch{
+				ClusterId:   creds[i].ClusterID,
+				KarmadaName: member.Name,
+				MatchedBy:   model.MatchedByEndpoint,
+			})
+			matched = append(matched, creds[i])
+			continue
+		}
+		result.MissingCredentials = append(result.MissingCredentials, member.Name)
+	}
+
+	for i, ci := range creds {
+		if ci.ClusterID == hostClusterName || used[i] {
+			continue
+		}
+		result.UnregisteredClusters = append(result.UnregisteredClusters, ci.ClusterID)
+	}
+	sort.Strings(result.UnregisteredClusters)
+
+	return matched, result
+}
+
+// karmadaClusterViews 는 Karmada 가 보고한 멤버 클러스터 상태를 이름순으로 변환한다.
+func karmadaClusterViews(members []karmada.MemberCluster) []model.KarmadaClusterView {
+	views := make([]model.KarmadaClusterView, 0, len(members))
+	for _, member := range members {
+		view := model.KarmadaClusterView{
+			Name:              member.Name,
+			SyncMode:          member.SyncMode,
+			KubernetesVersion: member.KubernetesVersion,
+			Ready:             "Unknown",
+			Allocatable:       member.ResourceSummary.Allocatable,
+			Allocating:        member.ResourceSummary.Allocating,
+			Allocated:         member.ResourceSummary.Allocated,
+		}
+		if ready, ok := member.ReadyCondition(); ok {
+			view.Ready = ready.Status
+			view.ReadyReason = ready.Reason
+			view.ReadyMessage = ready.Message
+		}
+		views = append(views, view)
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+	return views
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"testing"
+
+	"federation-metric-api/internal/karmada"
+	"federation-metric-api/model"
+)
+
+func TestReconcileClusters(t *testing.T) {
+	oldHost := hostClusterName
+	hostClusterName = "host"
+	defer func() { hostClusterName = oldHost }()
+
+	members := []karmada.MemberCluster{
+		{Name: "host", Endpoint: "https://host"},
+		{Name: "member-b", Endpoint: "https://b.example/"},
+		{Name: "member-a", Endpoint: "https://a.example"},
+		{Name: "renamed", Endpoint: "https://c.example"},
+		{Name: "no-secret", Endpoint: "https://d.example"},
+	}
+	creds := []model.ClusterCredential{
+		{ClusterID: "host", APIServerURL: "https://host"},
+		{ClusterID: "member-a", APIServerURL: "https://a-old.example"},
+		{ClusterID: "member-b", APIServerURL: "https://B.example"},
+		{ClusterID: "member-c", APIServerURL: "https://c.example"},
+		{ClusterID: "vault-only", APIServerURL: "https://e.example"},
+	}
+
+	matched, result := reconcileClusters(members, creds)
+
+	if len(matched) != 3 {
+		t.Fatalf("expected 3 matched credentials, got %d: %+v", len(matched), matched)
+	}
+	wantMatches := []model.ClusterMatch{
+		{ClusterId: "member-a", KarmadaName: "member-a", MatchedBy: model.MatchedByName},
+		{ClusterId: "member-b", KarmadaName: "member-b", MatchedBy: model.MatchedByName},
+		{ClusterId: "member-c", KarmadaName: "renamed", MatchedBy: model.MatchedByEndpoint},
+	}
+	for i, want := range wantMatches {
+		if result.Matched[i] != want {
+			t.Fatalf("matched[%d] = %+v, want %+v", i, result.Matched[i], want)
+		}
+	}
+
+	if len(result.MissingCredentials) != 1 || result.MissingCredentials[0] != "no-secret" {
+		t.Fatalf("unexpected missing credentials: %v", result.MissingCredentials)
+	}
+	if len(result.UnregisteredClusters) != 1 || result.UnregisteredClusters[0] != "vault-only" {
+		t.Fatalf("unexpected unregistered clusters: %v", result.UnregisteredClusters)
+	}
+	if len(result.EndpointMismatches) != 1 {
+		t.Fatalf("expected 1 endpoint mismatch, got %+v", result.EndpointMismatches)
+	}
+	mismatch := result.EndpointMismatches[0]
+	if mismatch.ClusterId != "member-a" || mismatch.KarmadaEndpoint != "https://a.example" || mismatch.CredentialEndpoint != "https://a-old.example" {
+		t.Fatalf("unexpected endpoint mismatch: %+v", mismatch)
+	}
+}
+
+func TestKarmadaClusterViews(t *testing.T) {
+	views := karmadaClusterViews([]karmada.MemberCluster{
+		{
+			Name:     "member-b",
+			SyncMode: karmada.SyncModePull,
+			Conditions: []karmada.Condition{
+				{Type: "Ready", Status: "False", Reason: "ClusterNotReachable"},
+			},
+			ResourceSummary: karmada.ResourceSummary{
+				Allocatable: map[string]string{"cpu": "4"},
+			},
+		},
+		{Name: "member-a", SyncMode: karmada.SyncModePush},
+	})
+
+	if len(views) != 2 || views[0].Name != "member-a" || views[1].Name != "member-b" {
+		t.Fatalf("unexpected views order: %+v", views)
+	}
+	if views[0].Ready != "Unknown" {
+		t.Fatalf("expected Unknown ready without condition, got %q", views[0].Ready)
+	}
+	if views[1].Ready != "False" || views[1].ReadyReason != "ClusterNotReachable" || views[1].SyncMode != karmada.SyncModePull {
+		t.Fatalf("unexpected member-b view: %+v", views[1])
+	}
+	if views[1].Allocatable["cpu"] != "4" {
+		t.Fatalf("unexpected allocatable: %v", views[1].Allocatable)
+	}
+}
+
+func TestMemberTargets_UsesKarmadaProxy(t *testing.T) {
+	oldHost := hostClusterName
+	oldApi := karmadaApi
+	oldToken := karmadaToken
+	oldProxy := karmadaProxyClusters
+	defer func() {
+		hostClusterName = oldHost
+		karmadaApi = oldApi
+		karmadaToken = oldToken
+		karmadaProxyClusters = oldProxy
+	}()
+	hostClusterName = "host"
+	karmadaApi = "https://karmada:5443/"
+	karmadaToken = "karmada-token"
+	karmadaProxyClusters = map[string]bool{"forced": true}
+
+	members := []karmada.MemberCluster{
+		{Name: "push", Endpoint: "https://push", SyncMode: karmada.SyncModePush},
+		{Name: "pull", Endpoint: "https://pull", SyncMode: karmada.SyncModePull},
+		{Name: "forced", Endpoint: "https://forced", SyncMode: karmada.SyncModePush},
+		{Name: "pull-no-secret", Endpoint: "https://pull2", SyncMode: karmada.SyncModePull},
+		{Name: "push-no-secret", Endpoint: "https://push2", SyncMode: karmada.SyncModePush},
+	}
+	creds := []model.ClusterCredential{
+		{ClusterID: "push", APIServerURL: "https://push", BearerToken: "t1"},
+		{ClusterID: "pull", APIServerURL: "https://pull", BearerToken: "t2"},
+		{ClusterID: "forced", APIServerURL: "https://forced", BearerToken: "t3"},
+	}
+
+	matched, reconciliation := reconcileClusters(members, creds)
+	targets := memberTargets(members, matched, reconciliation)
+
+	proxied := map[string]bool{}
+	for _, target := range targets {
+		proxied[target.cred.ClusterID] = target.proxy
+	}
+	want := map[string]bool{"forced": true, "pull": true, "push": false, "pull-no-secret": true}
+	if len(proxied) != len(want) {
+		t.Fatalf("unexpected targets: %+v", targets)
+	}
+	for id, proxy := range want {
+		if got, ok := proxied[id]; !ok || got != proxy {
+			t.Fatalf("target %q proxy = %v (present=%v), want %v", id, got, ok, proxy)
+		}
+	}
+
+	cfg, err := restConfigFor(collectTarget{karmadaName: "pull", proxy: true})
+	if err != nil {
+		t.Fatalf("restConfigFor returned error: %v", err)
+	}
+	if cfg.Host != "https://karmada:5443/apis/cluster.karmada.io/v1alpha1/clusters/pull/proxy" {
+		t.Fatalf("unexpected proxy host: %q", cfg.Host)
+	}
+	if cfg.BearerToken != "karmada-token" {
+		t.Fatalf("expected karmada token for proxy config, got %q", cfg.BearerToken)
+	}
+	if cfg.Insecure {
+		t.Fatalf("expected verified TLS for proxy config without KarmadaInsecure")
+	}
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"k8s.io/client-go/kubernetes"
+	"log"
+	"sync"
+)
+
+// clientRegistry 는 클러스터 ID 별로 clientset 을 보관해 수집 주기 간에 재사용한다.
+// 접속 정보의 fingerprint 가 바뀐 경우에만 clientset 을 다시 만든다.
+type clientRegistry struct {
+	mu      sync.Mutex
+	entries map[string]registryEntry
+}
+
+type registryEntry struct {
+	fingerprint string
+	clientset   kubernetes.Interface
+	insecure    bool
+}
+
+func newClientRegistry() *clientRegistry {
+	return &clientRegistry{entries: make(map[string]registryEntry)}
+}
+
+var clients = newClientRegistry()
+
+// fingerprint 는 clientset 구성에 영향을 주는 접속 정보의 해시이다.
+func fingerprint(target collectTarget) string {
+	h := sha256.New()
+	ci := target.cred
+	if target.proxy {
+		fmt.Fprintf(h, "proxy\x00%s\x00%s\x00%s\x00%s\x00%t", target.karmadaName, karmadaApi, karmadaToken, karmadaCaCert, karmadaInsecure)
+	} else {
+		fmt.Fprintf(h, "direct\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%t",
+			ci.APIServerURL, ci.BearerToken, ci.ClientCert, ci.ClientKey, ci.Kubeconfig, ci.CACert, ci.Insecure)
+	}
+	fmt.Fprintf(h, "\x00%s", collectTimeout)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// get 은 캐시된 clientset 을 반환하고, 없거나 접속 정보가 바뀌었으면 새로 만든다.
+// 두 번째 반환값은 해당 연결이 인증서 검증을 생략하는지 여부이다.
+func (r *clientRegistry) get(target collectTarget) (kubernetes.Interface, bool, error) {
+	id := target.cred.ClusterID
+	fp := fingerprint(target)
+
+	r.mu.Lock()
+	entry, ok := r.entries[id]
+	r.mu.Unlock()
+	if ok && entry.fingerprint == fp {
+		return entry.clientset, entry.insecure, nil
+	}
+
+	cfg, err := restConfigFor(target)
+	if err != nil {
+		return nil, false, err
+	}
+	clientset, err := NewKubeClient(cfg)
+	if err != nil {
+		return nil, cfg.Insecure, err
+	}
+	if ok {
+		log.Printf("%s 클러스터 접속 정보 변경, clientset 재생성", id)
+	}
+
+	r.mu.Lock()
+	r.entries[id] = registryEntry{fingerprint: fp, clientset: clientset, insecure: cfg.Insecure}
+	r.mu.Unlock()
+	return clientset, cfg.Insecure, nil
+}
+
+// retain 은 현재 수집 대상에 없는 클러스터의 clientset 을 제거한다.
+func (r *clientRegistry) retain(targets []collectTarget) {
+	keep := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		keep[target.cred.ClusterID] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id := range r.entries {
+		if !keep[id] {
+			delete(r.entries, id)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"testing"
+
+	"federation-metric-api/model"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestClientRegistry_ReusesRebuildsAndEvicts(t *testing.T) {
+	oldKube := NewKubeClient
+	defer func() { NewKubeClient = oldKube }()
+
+	built := 0
+	NewKubeClient = func(cfg *rest.Config) (kubernetes.Interface, error) {
+		built++
+		return fake.NewClientset(), nil
+	}
+
+	registry := newClientRegistry()
+	target := collectTarget{cred: model.ClusterCredential{ClusterID: "m1", APIServerURL: "https://m1", BearerToken: "t1"}}
+
+	first, _, err := registry.get(target)
+	if err != nil {
+		t.Fatalf("get returned error: %v", err)
+	}
+	second, _, _ := registry.get(target)
+	if first != second || built != 1 {
+		t.Fatalf("expected cached clientset to be reused, built=%d", built)
+	}
+
+	target.cred.BearerToken = "t2"
+	third, _, _ := registry.get(target)
+	if third == first || built != 2 {
+		t.Fatalf("expected clientset rebuild after credential change, built=%d", built)
+	}
+
+	registry.retain(nil)
+	if len(registry.entries) != 0 {
+		t.Fatalf("expected removed cluster to be evicted, got %d entries", len(registry.entries))
+	}
+	registry.get(target)
+	if built != 3 {
+		t.Fatalf("expected clientset rebuild after eviction, built=%d", built)
+	}
+}
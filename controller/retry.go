@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"federation-metric-api/model"
+)
+
+// 외부 의존성 이름
+const (
+	DependencyCredentials = "credentials"
+	DependencyKarmada     = "karmada"
+	DependencyBindings    = "karmada-bindings"
+	DependencyNats        = "nats"
+)
+
+var (
+	retryInitialBackoff = 5 * time.Second
+	retryMaxBackoff     = 5 * time.Minute
+)
+
+// backoff 는 연속 실패 횟수에 따른 재시도 대기 시간이다. 지수적으로 늘어나며 retryMaxBackoff 를
+// 넘지 않고, 여러 대상이 동시에 재시도하지 않도록 절반 범위의 지터를 더한다.
+func backoff(failures int) time.Duration {
+	d := retryInitialBackoff
+	for i := 1; i < failures && d < retryMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > retryMaxBackoff {
+		d = retryMaxBackoff
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// dependencyTracker 는 의존성별 연속 실패 횟수와 다음 재시도 시각을 기록한다.
+type dependencyTracker struct {
+	mu     sync.Mutex
+	states map[string]*model.DependencyStatus
+}
+
+func newDependencyTracker(names ...string) *dependencyTracker {
+	t := &dependencyTracker{states: make(map[string]*model.DependencyStatus)}
+	for _, name := range names {
+		t.states[name] = &model.DependencyStatus{Name: name}
+	}
+	return t
+}
+
+var dependencies = newDependencyTracker(DependencyCredentials, DependencyKarmada, DependencyNats)
+
+func (t *dependencyTracker) state(name string) *model.DependencyStatus {
+	s, ok := t.states[name]
+	if !ok {
+		s = &model.DependencyStatus{Name: name}
+		t.states[name] = s
+	}
+	return s
+}
+
+// due 는 마지막 실패의 백오프가 지나 다시 호출할 때가 되었는지 반환한다.
+func (t *dependencyTracker) due(name string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.state(name)
+	return s.NextRetryTime == nil || !now.Before(*s.NextRetryTime)
+}
+
+func (t *dependencyTracker) success(name string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.state(name)
+	now = now.UTC()
+	s.Healthy = true
+	s.Error = ""
+	s.ConsecutiveFailures = 0
+	s.LastSuccessTime = &now
+	s.NextRetryTime = nil
+}
+
+// failure 는 실패를 기록하고 다음 재시도 시각을 반환한다.
+func (t *dependencyTracker) failure(name string, err error, now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	dependencyErrors.WithLabelValues(name).Inc()
+	s := t.state(name)
+	s.Healthy = false
+	s.Error = err.Error()
+	s.ConsecutiveFailures++
+	next := now.Add(backoff(s.ConsecutiveFailures)).UTC()
+	s.NextRetryTime = &next
+	return next
+}
+
+// partial 은 일부 소스만 실패해 사용할 수 있는 결과를 얻은 경우를 기록한다. 실패로 기록해 백오프 후
+// 다시 호출하되, 결과는 적재했으므로 마지막 성공 시각도 갱신해 readiness 를 막지 않는다.
+func (t *dependencyTracker) partial(name string, err error, now time.Time) time.Time {
+	next := t.failure(name, err, now)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	loaded := now.UTC()
+	t.state(name).LastSuccessTime = &loaded
+	return next
+}
+
+// statuses 는 의존성 상태를 이름 순으로 복사해 반환한다.
+func (t *dependencyTracker) statuses() []model.DependencyStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]model.DependencyStatus, 0, len(t.states))
+	for _, s := range t.states {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Dependencies 는 외부 의존성의 현재 상태를 반환한다.
+func Dependencies() []model.DependencyStatus {
+	return dependencies.statuses()
+}
+
+// clusterBackoff 는 접속할 수 없는 클러스터를 백오프 동안 건너뛰기 위해 마지막 실패 결과를 보관한다.
+type clusterBackoff struct {
+	mu      sync.Mutex
+	entries map[string]clusterBackoffEntry
+}
+
+type clusterBackoffEntry struct {
+	status      model.ClusterStatus
+	fingerprint string
+	failures    int
+	next        time.Time
+}
+
+func newClusterBackoff() *clusterBackoff {
+	return &clusterBackoff{entries: make(map[string]clusterBackoffEntry)}
+}
+
+var clusterRetry = newClusterBackoff()
+
+// retriableState 는 재시도 간격을 늘려야 하는 수집 상태이다. 응답은 했지만 일부 지표를 얻지 못한
+// degraded, metrics-api-missing 은 매 주기 다시 수집한다.
+func retriableState(state string) bool {
+	switch state {
+	case model.CollectStateUnreachable, model.CollectStateUnauthorized, model.CollectStateTimeout:
+		return true
+	}
+	return false
+}
+
+// skip 은 클러스터가 백오프 중이면 마지막 실패 결과를 반환한다. 실패 이후 접속 정보의
+// fingerprint 가 바뀌었으면 기록을 지우고 바로 다시 수집하게 한다.
+func (b *clusterBackoff) skip(clusterID, fp string, now time.Time) (model.ClusterStatus, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.entries[clusterID]
+	if ok && e.fingerprint != fp {
+		delete(b.entries, clusterID)
+		return model.ClusterStatus{}, false
+	}
+	if !ok || !now.Before(e.next) {
+		return model.ClusterStatus{}, false
+	}
+	return e.status, true
+}
+
+// forget 은 클러스터의 백오프 기록을 지운다. 인증 정보 변경 이벤트를 받으면 호출한다.
+func (b *clusterBackoff) forget(clusterID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.entries, clusterID)
+}
+
+// record 는 수집 결과를 기록하고, 재시도 대상이면 다음 재시도 시각을 결과에 표시한다.
+// fp 는 수집에 사용한 접속 정보의 fingerprint 이다.
+func (b *clusterBackoff) record(cluster model.ClusterStatus, fp string, now time.Time) model.ClusterStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !retriableState(cluster.CollectState) {
+		delete(b.entries, cluster.ClusterId)
+		return cluster
+	}
+	e := b.entries[cluster.ClusterId]
+	e.fingerprint = fp
+	e.failures++
+	e.next = now.Add(backoff(e.failures))
+	next := e.next.UTC()
+	cluster.NextRetryTime = &next
+	e.status = cluster
+	b.entries[cluster.ClusterId] = e
+	return cluster
+}
+
+// retain 은 더 이상 수집 대상이 아닌 클러스터의 기록을 지운다.
+func (b *clusterBackoff) retain(targets []collectTarget) {
+	keep := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		keep[target.cred.ClusterID] = true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id := range b.entries {
+		if !keep[id] {
+			delete(b.entries, id)
+		}
+	}
+}
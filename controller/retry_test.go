@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"federation-metric-api/internal/adapter"
+	"federation-metric-api/model"
+	outnats "github.com/nats-io/nats.go"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestBackoff_GrowsWithJitterAndCaps(t *testing.T) {
+	oldInitial, oldMax := retryInitialBackoff, retryMaxBackoff
+	defer func() { retryInitialBackoff, retryMaxBackoff = oldInitial, oldMax }()
+	retryInitialBackoff = time.Second
+	retryMaxBackoff = 10 * time.Second
+
+	for failures, base := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: 10 * time.Second} {
+		for i := 0; i < 20; i++ {
+			d := backoff(failures)
+			if d < base/2 || d > base {
+				t.Fatalf("backoff(%d) = %s, want within [%s, %s]", failures, d, base/2, base)
+			}
+		}
+	}
+}
+
+func TestDependencyTracker_GatesRetriesUntilBackoffElapses(t *testing.T) {
+	tracker := newDependencyTracker(DependencyNats)
+	now := time.Now()
+
+	if !tracker.due(DependencyNats, now) {
+		t.Fatalf("expected a fresh dependency to be due")
+	}
+	next := tracker.failure(DependencyNats, errors.New("connection refused"), now)
+	if tracker.due(DependencyNats, now) {
+		t.Fatalf("expected dependency to back off after a failure")
+	}
+	if !tracker.due(DependencyNats, next) {
+		t.Fatalf("expected dependency to be due at its next retry time")
+	}
+
+	st := tracker.statuses()[0]
+	if st.Healthy || st.ConsecutiveFailures != 1 || st.Error != "connection refused" || st.NextRetryTime == nil {
+		t.Fatalf("unexpected failure status: %+v", st)
+	}
+
+	tracker.success(DependencyNats, next)
+	st = tracker.statuses()[0]
+	if !st.Healthy || st.ConsecutiveFailures != 0 || st.Error != "" || st.NextRetryTime != nil || st.LastSuccessTime == nil {
+		t.Fatalf("unexpected success status: %+v", st)
+	}
+}
+
+func TestCollectWithRetry_SkipsUnreachableClusterDuringBackoff(t *testing.T) {
+	restore := stubCollectors()
+	defer restore()
+
+	calls := 0
+	NewKubeClient = func(cfg *rest.Config) (kubernetes.Interface, error) {
+		calls++
+		return nil, errors.New("dial tcp: connection refused")
+	}
+
+	target := collectTarget{cred: model.ClusterCredential{ClusterID: "m1", APIServerURL: "https://m1", BearerToken: "t"}}
+	first := collectWithRetry(context.Background(), target)
+	if first.CollectState != model.CollectStateUnreachable || first.NextRetryTime == nil {
+		t.Fatalf("expected unreachable status with next retry time, got %+v", first)
+	}
+
+	second := collectWithRetry(context.Background(), target)
+	if calls != 1 {
+		t.Fatalf("expected cluster to be skipped during backoff, clientset built %d times", calls)
+	}
+	if second.CollectState != model.CollectStateUnreachable || second.NextRetryTime == nil || !second.NextRetryTime.Equal(*first.NextRetryTime) {
+		t.Fatalf("expected last failure to be reused, got %+v", second)
+	}
+
+	clusterRetry.retain(nil)
+	collectWithRetry(context.Background(), target)
+	if calls != 2 {
+		t.Fatalf("expected evicted cluster to be collected again, clientset built %d times", calls)
+	}
+}
+
+func TestCollectWithRetry_RetriesImmediatelyWhenCredentialsChange(t *testing.T) {
+	restore := stubCollectors()
+	defer restore()
+
+	calls := 0
+	NewKubeClient = func(cfg *rest.Config) (kubernetes.Interface, error) {
+		calls++
+		return nil, errors.New("dial tcp: connection refused")
+	}
+
+	target := collectTarget{cred: model.ClusterCredential{ClusterID: "m1", APIServerURL: "https://m1", BearerToken: "old"}}
+	collectWithRetry(context.Background(), target)
+
+	// 접속 정보가 바뀌면 백오프 중이어도 새 정보로 다시 수집한다.
+	target.cred.BearerToken = "new"
+	collectWithRetry(context.Background(), target)
+	if calls != 2 {
+		t.Fatalf("expected changed credentials to bypass the backoff, clientset built %d times", calls)
+	}
+
+	// 인증 정보 변경 이벤트를 받아도 백오프 기록을 지운다.
+	events := make(chan model.CredentialEvent, 1)
+	events <- model.CredentialEvent{Type: model.CredentialUpdated, ClusterID: "m1"}
+	drainEvents(events)
+	collectWithRetry(context.Background(), target)
+	if calls != 3 {
+		t.Fatalf("expected a credential event to clear the backoff, clientset built %d times", calls)
+	}
+}
+
+// flakyNats 는 처음 failures 번의 KV 버킷 요청에 실패하는 NATS 클라이언트이다.
+type flakyNats struct {
+	mu       sync.Mutex
+	failures int
+	kv       outnats.KeyValue
+}
+
+func (f *flakyNats) CreateKeyValue(bucket string) (outnats.KeyValue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("jetstream not enabled")
+	}
+	return f.kv, nil
+}
+
+func (f *flakyNats) KeyValue(bucket string) (outnats.KeyValue, error) {
+	return nil, errors.New("bucket not found")
+}
+
+func TestRepeatMetric_RecoversFromNatsAndCredentialFailures(t *testing.T) {
+	restore := stubCollectors()
+	oldRepeat := repeatTime
+	oldKarm := NewKarmadaClient
+	oldNats := NewNatsClient
+	oldGet := GetClusterInfos
+	oldDeps := dependencies
+	oldInitial := retryInitialBackoff
+	defer func() {
+		restore()
+		repeatTime = oldRepeat
+		NewKarmadaClient = oldKarm
+		NewNatsClient = oldNats
+		GetClusterInfos = oldGet
+		dependencies = oldDeps
+		retryInitialBackoff = oldInitial
+	}()
+
+	repeatTime = 1
+	retryInitialBackoff = 10 * time.Millisecond
+	dependencies = newDependencyTracker(DependencyCredentials, DependencyKarmada, DependencyNats)
+
+	fakeStore := &fakeKV{}
+	NewKarmadaClient = func() KarmadaClient { return &fakeKarm{} }
+	NewNatsClient = func() NatsClient { return &flakyNats{failures: 1, kv: fakeStore} }
+	GetClusterInfos = func() ([]model.ClusterCredential, error) {
+		return nil, errors.New("vault sealed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RepeatMetric(ctx)
+	}()
+	time.Sleep(2500 * time.Millisecond)
+	cancel()
+	<-done
+
+	puts := fakeStore.stored()
+	if len(puts) == 0 {
+		t.Fatalf("expected metrics to be published once NATS recovered")
+	}
+	var ms model.MetricStatus
+	if err := json.Unmarshal(puts[len(puts)-1], &ms); err != nil {
+		t.Fatalf("invalid MetricStatus JSON stored in KV: %v", err)
+	}
+	byName := map[string]model.DependencyStatus{}
+	for _, d := range ms.Dependencies {
+		byName[d.Name] = d
+	}
+	if c := byName[DependencyCredentials]; c.Healthy || c.Error != "vault sealed" || c.ConsecutiveFailures == 0 {
+		t.Fatalf("expected credential failure in snapshot, got %+v", c)
+	}
+	if n := byName[DependencyNats]; !n.Healthy {
+		t.Fatalf("expected NATS to be healthy after recovery, got %+v", n)
+	}
+	if readiness := Readiness(); readiness.Components[DependencyCredentials].Status != model.HealthDown {
+		t.Fatalf("expected credentials to be down until loaded once, got %+v", readiness.Components[DependencyCredentials])
+	}
+}
+
+func TestRepeatMetric_UsesPartialCredentialsAndReportsFailure(t *testing.T) {
+	restore := stubCollectors()
+	oldRepeat := repeatTime
+	oldKarm := NewKarmadaClient
+	oldNats := NewNatsClient
+	oldGet := GetClusterInfos
+	oldDeps := dependencies
+	oldHost := hostClusterName
+	oldLatest := latest
+	defer func() {
+		restore()
+		repeatTime = oldRepeat
+		NewKarmadaClient = oldKarm
+		NewNatsClient = oldNats
+		GetClusterInfos = oldGet
+		dependencies = oldDeps
+		hostClusterName = oldHost
+		latest = oldLatest
+	}()
+
+	repeatTime = 1
+	hostClusterName = "host-1"
+	latest = &snapshotStore{}
+	dependencies = newDependencyTracker(DependencyCredentials, DependencyKarmada, DependencyNats)
+	NewKarmadaClient = func() KarmadaClient { return &fakeKarm{} }
+	NewNatsClient = func() NatsClient { return &fakeNats{kv: &fakeKV{}} }
+	GetClusterInfos = func() ([]model.ClusterCredential, error) {
+		return []model.ClusterCredential{{ClusterID: "host-1", APIServerURL: "https://host"}},
+			&adapter.PartialError{Err: errors.New("vault: sealed")}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RepeatMetric(ctx)
+	}()
+	time.Sleep(1500 * time.Millisecond)
+	cancel()
+	<-done
+
+	ms, ok := LatestSnapshot()
+	if !ok || ms.HostClusterStatus.ClusterId != "host-1" {
+		t.Fatalf("expected clusters from the healthy sources to be collected, got %+v", ms.HostClusterStatus)
+	}
+	for _, d := range ms.Dependencies {
+		if d.Name == DependencyCredentials && (d.Healthy || !strings.Contains(d.Error, "sealed")) {
+			t.Fatalf("expected the partial failure in dependencies, got %+v", d)
+		}
+	}
+	// 처음부터 일부 소스만 실패했어도 사용할 인증 정보를 적재했으므로 준비된 상태로 본다.
+	if c := Readiness().Components[DependencyCredentials]; c.Status != model.HealthUp || c.Details["degraded"] != true {
+		t.Fatalf("expected partially loaded credentials to be ready but degraded, got %+v", c)
+	}
+}
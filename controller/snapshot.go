@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"federation-metric-api/internal/history"
+	"federation-metric-api/model"
+)
+
+// snapshotStore 는 마지막으로 만든 스냅샷이다. NATS 게시 여부와 관계없이 갱신되어
+// HTTP 조회와 Prometheus 수집에 사용된다.
+type snapshotStore struct {
+	mu     sync.RWMutex
+	status model.MetricStatus
+	ok     bool
+}
+
+var latest = &snapshotStore{}
+
+func (s *snapshotStore) set(status model.MetricStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.ok = true
+}
+
+func (s *snapshotStore) get() (model.MetricStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status, s.ok
+}
+
+// LatestSnapshot 은 마지막으로 수집한 스냅샷을 반환한다. 아직 수집 전이면 false 를 반환한다.
+func LatestSnapshot() (model.MetricStatus, bool) {
+	return latest.get()
+}
+
+// History 는 스냅샷마다 기록하는 클러스터 사용률 이력이다.
+var History = history.NewStore(history.DefaultResolutions)
+
+// historyPath 가 비어 있지 않으면 이력을 historySaveInterval 마다 이 파일에 저장하고 시작할 때 복원한다.
+var (
+	historyPath         string
+	historySaveInterval = 5 * time.Minute
+)
+
+// SaveHistory 는 이력 파일이 설정되어 있으면 현재 이력을 저장한다. 종료 직전에도 호출된다.
+func SaveHistory() error {
+	if historyPath == "" {
+		return nil
+	}
+	return History.Save(historyPath)
+}
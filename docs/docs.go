@@ -14,17 +14,766 @@ const docTemplate = `{
     },
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
-    "paths": {}
+    "paths": {
+        "/api/v1/clusters": {
+            "get": {
+                "description": "마지막 스냅샷에 포함된 호스트·멤버 클러스터와 각 클러스터의 수집 상태를 반환합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clusters"
+                ],
+                "summary": "클러스터 목록 조회",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ClusterSummary"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "아직 수집된 스냅샷이 없음",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/clusters/{clusterId}": {
+            "get": {
+                "description": "마지막 스냅샷에서 clusterId 에 해당하는 클러스터의 상태와 지표를 노드별 상태와 함께 반환합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clusters"
+                ],
+                "summary": "클러스터 상태 조회",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "클러스터 ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ClusterStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "스냅샷에 없는 클러스터",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "아직 수집된 스냅샷이 없음",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/clusters/{clusterId}/history": {
+            "get": {
+                "description": "from, to 사이의 CPU/메모리 실사용률과 요청률 시계열을 반환합니다. 원본(raw)은 30초, 5m·1h 는 구간 평균입니다.\nresolution 을 생략하면 from 시점까지 보관하는 가장 세밀한 해상도를 사용합니다.\n이력은 수집기 파드가 보관하며 기본 보관 기간은 raw 6시간, 5m 2일, 1h 7일입니다(HISTORY_RETENTION).\nHISTORY_PATH 파일에 주기적으로 저장해 재시작 후 복원하며, 파일이 없어지면 그 이전 이력은 조회되지 않습니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clusters"
+                ],
+                "summary": "클러스터 사용률 이력 조회",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "클러스터 ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "시작 시각 (RFC3339 또는 Unix 초, 기본값 to 1시간 전)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "종료 시각 (RFC3339 또는 Unix 초, 기본값 현재)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "raw",
+                            "5m",
+                            "1h"
+                        ],
+                        "type": "string",
+                        "description": "해상도",
+                        "name": "resolution",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ClusterHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "잘못된 조회 조건",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "이력이 없는 클러스터",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/snapshot": {
+            "get": {
+                "description": "호스트·멤버 클러스터 지표, Karmada 매칭 결과, 의존성 상태를 포함한 마지막 스냅샷을 반환합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshot"
+                ],
+                "summary": "최신 페더레이션 스냅샷 조회",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MetricStatus"
+                        }
+                    },
+                    "503": {
+                        "description": "아직 수집된 스냅샷이 없음",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.ClusterHistory": {
+            "type": "object",
+            "properties": {
+                "clusterId": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UsagePoint"
+                    }
+                },
+                "resolution": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ClusterMatch": {
+            "type": "object",
+            "properties": {
+                "clusterId": {
+                    "type": "string"
+                },
+                "karmadaName": {
+                    "type": "string"
+                },
+                "matchedBy": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ClusterStatus": {
+            "type": "object",
+            "properties": {
+                "accessMode": {
+                    "type": "string"
+                },
+                "clusterId": {
+                    "type": "string"
+                },
+                "collectError": {
+                    "type": "string"
+                },
+                "collectState": {
+                    "type": "string"
+                },
+                "insecureTls": {
+                    "type": "boolean"
+                },
+                "lastSuccessTime": {
+                    "type": "string"
+                },
+                "namespaceCount": {
+                    "type": "integer"
+                },
+                "namespaces": {
+                    "description": "Namespaces 는 사용량 상위 네임스페이스이고, NamespaceCount 는 잘리기 전 네임스페이스 수이다.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NamespaceUsage"
+                    }
+                },
+                "nextRetryTime": {
+                    "type": "string"
+                },
+                "nodeSummary": {
+                    "$ref": "#/definitions/model.NodeSummary"
+                },
+                "nodes": {
+                    "description": "Nodes 는 노드별 상태이다. REST API 로만 제공하며 NATS KV 스냅샷에는 넣지 않는다.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NodeStatus"
+                    }
+                },
+                "realTimeUsage": {
+                    "$ref": "#/definitions/model.NodeUsageFloat"
+                },
+                "realTimeUsageCollected": {
+                    "description": "RealTimeUsageCollected, RequestUsageCollected 는 이번 수집에서 각 사용률을 얻었는지이다.\nfalse 이면 사용률 값은 0 이 아니라 알 수 없는 값이다.",
+                    "type": "boolean"
+                },
+                "requestUsage": {
+                    "$ref": "#/definitions/model.NodeUsageFloat"
+                },
+                "requestUsageCollected": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ClusterSummary": {
+            "type": "object",
+            "properties": {
+                "accessMode": {
+                    "type": "string"
+                },
+                "clusterId": {
+                    "type": "string"
+                },
+                "collectError": {
+                    "type": "string"
+                },
+                "collectState": {
+                    "type": "string"
+                },
+                "lastSuccessTime": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "consecutiveFailures": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "lastSuccessTime": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nextRetryTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.EndpointMismatch": {
+            "type": "object",
+            "properties": {
+                "clusterId": {
+                    "type": "string"
+                },
+                "credentialEndpoint": {
+                    "type": "string"
+                },
+                "karmadaEndpoint": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.FederatedWorkload": {
+            "type": "object",
+            "properties": {
+                "binding": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "placements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WorkloadPlacement"
+                    }
+                },
+                "replicas": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "usage": {
+                    "$ref": "#/definitions/model.NodeResources"
+                }
+            }
+        },
+        "model.HostClusterStatus": {
+            "type": "object",
+            "properties": {
+                "accessMode": {
+                    "type": "string"
+                },
+                "clusterId": {
+                    "type": "string"
+                },
+                "collectError": {
+                    "type": "string"
+                },
+                "collectState": {
+                    "type": "string"
+                },
+                "insecureTls": {
+                    "type": "boolean"
+                },
+                "lastSuccessTime": {
+                    "type": "string"
+                },
+                "namespaceCount": {
+                    "type": "integer"
+                },
+                "namespaces": {
+                    "description": "Namespaces 는 사용량 상위 네임스페이스이고, NamespaceCount 는 잘리기 전 네임스페이스 수이다.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NamespaceUsage"
+                    }
+                },
+                "nextRetryTime": {
+                    "type": "string"
+                },
+                "nodeSummary": {
+                    "$ref": "#/definitions/model.NodeSummary"
+                },
+                "nodes": {
+                    "description": "Nodes 는 노드별 상태이다. REST API 로만 제공하며 NATS KV 스냅샷에는 넣지 않는다.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NodeStatus"
+                    }
+                },
+                "realTimeUsage": {
+                    "$ref": "#/definitions/model.NodeUsageFloat"
+                },
+                "realTimeUsageCollected": {
+                    "description": "RealTimeUsageCollected, RequestUsageCollected 는 이번 수집에서 각 사용률을 얻었는지이다.\nfalse 이면 사용률 값은 0 이 아니라 알 수 없는 값이다.",
+                    "type": "boolean"
+                },
+                "requestUsage": {
+                    "$ref": "#/definitions/model.NodeUsageFloat"
+                },
+                "requestUsageCollected": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.KarmadaClusterView": {
+            "type": "object",
+            "properties": {
+                "allocatable": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "allocated": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "allocating": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "kubernetesVersion": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ready": {
+                    "type": "string"
+                },
+                "readyMessage": {
+                    "type": "string"
+                },
+                "readyReason": {
+                    "type": "string"
+                },
+                "syncMode": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MemberClusterStatus": {
+            "type": "object",
+            "properties": {
+                "accessMode": {
+                    "type": "string"
+                },
+                "clusterId": {
+                    "type": "string"
+                },
+                "collectError": {
+                    "type": "string"
+                },
+                "collectState": {
+                    "type": "string"
+                },
+                "insecureTls": {
+                    "type": "boolean"
+                },
+                "lastSuccessTime": {
+                    "type": "string"
+                },
+                "namespaceCount": {
+                    "type": "integer"
+                },
+                "namespaces": {
+                    "description": "Namespaces 는 사용량 상위 네임스페이스이고, NamespaceCount 는 잘리기 전 네임스페이스 수이다.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NamespaceUsage"
+                    }
+                },
+                "nextRetryTime": {
+                    "type": "string"
+                },
+                "nodeSummary": {
+                    "$ref": "#/definitions/model.NodeSummary"
+                },
+                "nodes": {
+                    "description": "Nodes 는 노드별 상태이다. REST API 로만 제공하며 NATS KV 스냅샷에는 넣지 않는다.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NodeStatus"
+                    }
+                },
+                "realTimeUsage": {
+                    "$ref": "#/definitions/model.NodeUsageFloat"
+                },
+                "realTimeUsageCollected": {
+                    "description": "RealTimeUsageCollected, RequestUsageCollected 는 이번 수집에서 각 사용률을 얻었는지이다.\nfalse 이면 사용률 값은 0 이 아니라 알 수 없는 값이다.",
+                    "type": "boolean"
+                },
+                "requestUsage": {
+                    "$ref": "#/definitions/model.NodeUsageFloat"
+                },
+                "requestUsageCollected": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MetricStatus": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DependencyStatus"
+                    }
+                },
+                "federatedWorkloadCount": {
+                    "type": "integer"
+                },
+                "federatedWorkloads": {
+                    "description": "FederatedWorkloads 는 사용량 상위 워크로드이고, FederatedWorkloadCount 는 잘리기 전 워크로드 수이다.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FederatedWorkload"
+                    }
+                },
+                "hostClusterStatus": {
+                    "$ref": "#/definitions/model.HostClusterStatus"
+                },
+                "karmadaClusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.KarmadaClusterView"
+                    }
+                },
+                "memberClusterStatus": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MemberClusterStatus"
+                    }
+                },
+                "reconciliation": {
+                    "$ref": "#/definitions/model.Reconciliation"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.NamespaceUsage": {
+            "type": "object",
+            "properties": {
+                "limits": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "pods": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "usage": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "workloads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WorkloadUsage"
+                    }
+                }
+            }
+        },
+        "model.NodeResources": {
+            "type": "object",
+            "properties": {
+                "cpu": {
+                    "type": "number"
+                },
+                "memory": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NodeStatus": {
+            "type": "object",
+            "properties": {
+                "allocatable": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "architecture": {
+                    "type": "string"
+                },
+                "capacity": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "kubeletVersion": {
+                    "type": "string"
+                },
+                "nodeName": {
+                    "type": "string"
+                },
+                "ready": {
+                    "type": "string"
+                },
+                "usage": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "usageRatio": {
+                    "$ref": "#/definitions/model.NodeUsageFloat"
+                }
+            }
+        },
+        "model.NodeSummary": {
+            "type": "object",
+            "properties": {
+                "readyNum": {
+                    "type": "integer"
+                },
+                "totalNum": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NodeUsageFloat": {
+            "type": "object",
+            "properties": {
+                "cpu": {
+                    "type": "number"
+                },
+                "memory": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.Reconciliation": {
+            "type": "object",
+            "properties": {
+                "endpointMismatches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.EndpointMismatch"
+                    }
+                },
+                "matched": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ClusterMatch"
+                    }
+                },
+                "missingCredentials": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unregisteredClusters": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.UsagePoint": {
+            "type": "object",
+            "properties": {
+                "realTimeUsage": {
+                    "$ref": "#/definitions/model.NodeUsageFloat"
+                },
+                "requestUsage": {
+                    "$ref": "#/definitions/model.NodeUsageFloat"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WorkloadPlacement": {
+            "type": "object",
+            "properties": {
+                "cluster": {
+                    "type": "string"
+                },
+                "pods": {
+                    "type": "integer"
+                },
+                "replicas": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "usage": {
+                    "$ref": "#/definitions/model.NodeResources"
+                }
+            }
+        },
+        "model.WorkloadUsage": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "type": "string"
+                },
+                "limits": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pods": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "$ref": "#/definitions/model.NodeResources"
+                },
+                "usage": {
+                    "$ref": "#/definitions/model.NodeResources"
+                }
+            }
+        }
+    }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
+	Version:          "1.0",
 	Host:             "",
-	BasePath:         "",
+	BasePath:         "/",
 	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Title:            "K-PaaS Federation Collector API",
+	Description:      "페더레이션 호스트·멤버 클러스터의 최신 수집 결과를 조회합니다.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
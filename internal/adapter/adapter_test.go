@@ -1,11 +1,24 @@
 package adapter
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
+	"federation-metric-api/config"
+	"federation-metric-api/internal/karmada"
 	"federation-metric-api/internal/vault"
 	"federation-metric-api/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 type fakeVaultClient struct {
@@ -58,6 +71,7 @@ func (f *fakeVaultFull) GetClusterInfos() ([]model.ClusterCredential, error) {
 
 func TestGetClusterInfos_Success(t *testing.T) {
 	oldNew := newVaultClient
+	vaultClient = nil
 	newVaultClient = func(cfg *vault.Config) (VaultClusterInfoClient, error) {
 		return &fakeVaultFull{
 			infos: []model.ClusterCredential{
@@ -65,7 +79,10 @@ func TestGetClusterInfos_Success(t *testing.T) {
 			},
 		}, nil
 	}
-	defer func() { newVaultClient = oldNew }()
+	defer func() {
+		newVaultClient = oldNew
+		vaultClient = nil
+	}()
 
 	got, err := GetClusterInfos()
 	if err != nil {
@@ -78,10 +95,14 @@ func TestGetClusterInfos_Success(t *testing.T) {
 
 func TestGetClusterInfos_NewClientError(t *testing.T) {
 	oldNew := newVaultClient
+	vaultClient = nil
 	newVaultClient = func(cfg *vault.Config) (VaultClusterInfoClient, error) {
 		return nil, errors.New("cannot-init-client")
 	}
-	defer func() { newVaultClient = oldNew }()
+	defer func() {
+		newVaultClient = oldNew
+		vaultClient = nil
+	}()
 
 	_, err := GetClusterInfos()
 	if err == nil {
@@ -91,13 +112,387 @@ func TestGetClusterInfos_NewClientError(t *testing.T) {
 
 func TestGetClusterInfos_LoadError(t *testing.T) {
 	oldNew := newVaultClient
+	vaultClient = nil
 	newVaultClient = func(cfg *vault.Config) (VaultClusterInfoClient, error) {
 		return &fakeVaultFull{err: errors.New("fetch-failed")}, nil
 	}
-	defer func() { newVaultClient = oldNew }()
+	defer func() {
+		newVaultClient = oldNew
+		vaultClient = nil
+	}()
 
 	_, err := GetClusterInfos()
 	if err == nil {
 		t.Fatalf("expected error, got nil")
 	}
 }
+
+type closableVault struct {
+	fakeVaultFull
+	closed bool
+}
+
+func (c *closableVault) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestGetClusterInfos_ReusesClientAndCloses(t *testing.T) {
+	oldNew := newVaultClient
+	vaultClient = nil
+	created := 0
+	fake := &closableVault{}
+	newVaultClient = func(cfg *vault.Config) (VaultClusterInfoClient, error) {
+		created++
+		return fake, nil
+	}
+	defer func() {
+		newVaultClient = oldNew
+		vaultClient = nil
+	}()
+
+	for i := 0; i < 3; i++ {
+		if _, err := GetClusterInfos(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("expected vault client to be created once, got %d", created)
+	}
+
+	if err := Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if !fake.closed {
+		t.Fatalf("expected vault client to be closed")
+	}
+	if vaultClient != nil {
+		t.Fatalf("expected shared vault client to be cleared")
+	}
+}
+
+const testMultiKubeconfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example:6443
+    certificate-authority-data: Y2E=
+- name: edge
+  cluster:
+    server: https://edge.example:6443
+    insecure-skip-tls-verify: true
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+- name: edge
+  context:
+    cluster: edge
+    user: edge
+users:
+- name: dev
+  user:
+    token: dev-token
+- name: edge
+  user:
+    token: edge-token
+`
+
+func TestKubeconfigAdapter_OneClusterPerContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testMultiKubeconfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+
+	got, err := NewKubeconfigAdapter(path).GetClusterInfos()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].ClusterID != "dev" || got[1].ClusterID != "edge" {
+		t.Fatalf("unexpected clusters: %+v", got)
+	}
+	if got[0].APIServerURL != "https://dev.example:6443" || got[0].CACert != "ca" || got[0].Insecure {
+		t.Fatalf("unexpected dev cluster: %+v", got[0])
+	}
+	if !got[1].Insecure {
+		t.Fatalf("expected edge cluster to be insecure")
+	}
+
+	cfg, err := clientcmd.RESTConfigFromKubeConfig([]byte(got[1].Kubeconfig))
+	if err != nil {
+		t.Fatalf("per-context kubeconfig is invalid: %v", err)
+	}
+	if cfg.Host != "https://edge.example:6443" || cfg.BearerToken != "edge-token" {
+		t.Fatalf("per-context kubeconfig points at wrong cluster: host=%s token=%s", cfg.Host, cfg.BearerToken)
+	}
+	if strings.Contains(got[1].Kubeconfig, "dev-token") {
+		t.Fatalf("per-context kubeconfig leaks other contexts' credentials")
+	}
+
+	if _, err := NewKubeconfigAdapter(filepath.Join(t.TempDir(), "missing")).GetClusterInfos(); err == nil {
+		t.Fatalf("expected error for missing kubeconfig")
+	}
+}
+
+func TestSecretAdapter_ReadsClusterSecrets(t *testing.T) {
+	secret := func(name string, labels map[string]string, data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "federation", Labels: labels},
+			Data:       map[string][]byte{},
+		}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+	client := fake.NewClientset(
+		secret("member-a", map[string]string{"role": "cluster"}, map[string]string{
+			"apiUrl": "https://a.example", "token": "token-a", "caCert": "ca-a",
+		}),
+		secret("renamed", map[string]string{"role": "cluster", ClusterNameLabel: "member-b"}, map[string]string{
+			"apiUrl": "https://b.example", "clientCert": "cert", "clientKey": "key", "insecure": "true",
+		}),
+		secret("no-auth", map[string]string{"role": "cluster"}, map[string]string{"apiUrl": "https://c.example"}),
+		secret("unrelated", nil, map[string]string{"apiUrl": "https://d.example", "token": "t"}),
+	)
+
+	a := &secretAdapter{client: client, namespace: "federation", selector: "role=cluster"}
+	got, err := a.GetClusterInfos()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].ClusterID < got[j].ClusterID })
+
+	want := []model.ClusterCredential{
+		{ClusterID: "member-a", APIServerURL: "https://a.example", BearerToken: "token-a", CACert: "ca-a"},
+		{ClusterID: "member-b", APIServerURL: "https://b.example", ClientCert: "cert", ClientKey: "key", Insecure: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d clusters, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("index %d mismatch: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+type fakeKarmadaSecrets struct {
+	members []karmada.MemberCluster
+	secrets map[string]map[string][]byte
+}
+
+func (f *fakeKarmadaSecrets) GetMemberClusters(ctx context.Context) ([]karmada.MemberCluster, error) {
+	return f.members, nil
+}
+
+func (f *fakeKarmadaSecrets) GetSecret(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+	data, ok := f.secrets[namespace+"/"+name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func TestKarmadaAdapter_ReadsSecretRefs(t *testing.T) {
+	fake := &fakeKarmadaSecrets{
+		members: []karmada.MemberCluster{
+			{Name: "push", Endpoint: "https://push.example", SecretRef: &karmada.SecretRef{Namespace: "karmada-cluster", Name: "push"}},
+			{Name: "pull", Endpoint: "https://pull.example", SyncMode: karmada.SyncModePull},
+			{Name: "missing", Endpoint: "https://missing.example", SecretRef: &karmada.SecretRef{Namespace: "karmada-cluster", Name: "missing"}},
+			{Name: "insecure", Endpoint: "https://insecure.example", InsecureSkipTLSVerification: true, SecretRef: &karmada.SecretRef{Namespace: "karmada-cluster", Name: "insecure"}},
+		},
+		secrets: map[string]map[string][]byte{
+			"karmada-cluster/push":     {"token": []byte("push-token"), "caBundle": []byte("push-ca")},
+			"karmada-cluster/insecure": {"token": []byte("insecure-token")},
+		},
+	}
+
+	got, err := NewKarmadaAdapter(fake).GetClusterInfos()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []model.ClusterCredential{
+		{ClusterID: "push", APIServerURL: "https://push.example", BearerToken: "push-token", CACert: "push-ca"},
+		{ClusterID: "insecure", APIServerURL: "https://insecure.example", BearerToken: "insecure-token", Insecure: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d clusters, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("index %d mismatch: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNewAdapter_SelectsSource(t *testing.T) {
+	oldEnv := *config.Env
+	defer func() { *config.Env = oldEnv }()
+
+	if a, err := NewAdapter(""); err != nil || a != (vaultAdapter{}) {
+		t.Fatalf("expected vault source by default, got %T, %v", a, err)
+	}
+
+	if _, err := NewAdapter(SourceKubeconfig); err == nil {
+		t.Fatalf("expected error without ClusterKubeconfig")
+	}
+	config.Env.ClusterKubeconfig = "/tmp/kubeconfig"
+	if a, err := NewAdapter("Kubeconfig"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, ok := a.(*kubeconfigAdapter); !ok {
+		t.Fatalf("expected kubeconfig source, got %T", a)
+	}
+
+	if _, err := NewAdapter(SourceSecret); err == nil {
+		t.Fatalf("expected error without ClusterSecretNamespace")
+	}
+	if _, err := NewAdapter("consul"); err == nil {
+		t.Fatalf("expected error for unsupported source")
+	}
+}
+
+func TestCompositeAdapter_MergesByPrecedence(t *testing.T) {
+	a := &compositeAdapter{sources: []namedAdapter{
+		{name: "secret", adapter: &fakeVaultClient{infos: []model.ClusterCredential{
+			{ClusterID: "migrated", APIServerURL: "https://migrated.example/", BearerToken: "new"},
+			{ClusterID: "moved", APIServerURL: "https://new.example", BearerToken: "new"},
+		}}},
+		{name: "broken", adapter: &fakeVaultClient{err: errors.New("boom")}},
+		{name: "vault", adapter: &fakeVaultClient{infos: []model.ClusterCredential{
+			{ClusterID: "legacy", APIServerURL: "https://legacy.example", BearerToken: "old"},
+			{ClusterID: "migrated", APIServerURL: "https://MIGRATED.example", BearerToken: "old"},
+			{ClusterID: "moved", APIServerURL: "https://old.example", BearerToken: "old"},
+		}}},
+	}}
+
+	got, err := a.GetClusterInfos()
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected a partial failure for the broken source, got %v", err)
+	}
+	want := []model.ClusterCredential{
+		{ClusterID: "migrated", APIServerURL: "https://migrated.example/", BearerToken: "new"},
+		{ClusterID: "moved", APIServerURL: "https://new.example", BearerToken: "new"},
+		{ClusterID: "legacy", APIServerURL: "https://legacy.example", BearerToken: "old"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d clusters, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("index %d mismatch: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	_, conflicts := a.merge([][]model.ClusterCredential{
+		{{ClusterID: "moved", APIServerURL: "https://new.example"}},
+		nil,
+		{{ClusterID: "moved", APIServerURL: "https://old.example"}, {ClusterID: "other", APIServerURL: "https://o.example"}},
+	})
+	wantConflict := Conflict{ClusterID: "moved", Source: "secret", URL: "https://new.example", IgnoredSource: "vault", IgnoredURL: "https://old.example"}
+	if len(conflicts) != 1 || conflicts[0] != wantConflict {
+		t.Fatalf("unexpected conflicts: %+v", conflicts)
+	}
+}
+
+func TestCompositeAdapter_WarnsOncePerConflict(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	vault := &fakeVaultClient{infos: []model.ClusterCredential{{ClusterID: "moved", APIServerURL: "https://old.example"}}}
+	a := &compositeAdapter{sources: []namedAdapter{
+		{name: "secret", adapter: &fakeVaultClient{infos: []model.ClusterCredential{{ClusterID: "moved", APIServerURL: "https://new.example"}}}},
+		{name: "vault", adapter: vault},
+	}}
+	warnings := func() int {
+		defer buf.Reset()
+		return strings.Count(buf.String(), "moved 클러스터 주소가 소스마다 다릅니다")
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := a.GetClusterInfos(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := warnings(); n != 1 {
+		t.Fatalf("expected the same conflict to be warned once, got %d warnings", n)
+	}
+
+	// 충돌 내용이 바뀌면 다시 경고한다.
+	vault.infos = []model.ClusterCredential{{ClusterID: "moved", APIServerURL: "https://other.example"}}
+	a.GetClusterInfos()
+	if n := warnings(); n != 1 {
+		t.Fatalf("expected a changed conflict to be warned again, got %d warnings", n)
+	}
+
+	// 해소된 뒤 다시 충돌하면 다시 경고한다.
+	vault.infos = nil
+	a.GetClusterInfos()
+	vault.infos = []model.ClusterCredential{{ClusterID: "moved", APIServerURL: "https://other.example"}}
+	a.GetClusterInfos()
+	if n := warnings(); n != 1 {
+		t.Fatalf("expected a recurring conflict to be warned again, got %d warnings", n)
+	}
+}
+
+func TestCompositeAdapter_ReusesLastResultOfFailedSource(t *testing.T) {
+	secret := &fakeVaultClient{infos: []model.ClusterCredential{{ClusterID: "migrated", APIServerURL: "https://m.example"}}}
+	vault := &fakeVaultClient{infos: []model.ClusterCredential{{ClusterID: "legacy", APIServerURL: "https://l.example"}}}
+	a := &compositeAdapter{sources: []namedAdapter{{name: "secret", adapter: secret}, {name: "vault", adapter: vault}}}
+
+	if _, err := a.GetClusterInfos(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	vault.err = errors.New("vault down")
+	secret.infos = append(secret.infos, model.ClusterCredential{ClusterID: "new", APIServerURL: "https://n.example"})
+	got, err := a.GetClusterInfos()
+	var partial *PartialError
+	if !errors.As(err, &partial) || !strings.Contains(err.Error(), "vault down") {
+		t.Fatalf("expected a partial failure naming the failed source, got %v", err)
+	}
+	ids := make([]string, 0, len(got))
+	for _, info := range got {
+		ids = append(ids, info.ClusterID)
+	}
+	if strings.Join(ids, ",") != "migrated,new,legacy" {
+		t.Fatalf("expected the failed source's last clusters to be kept, got %v", ids)
+	}
+}
+
+func TestCompositeAdapter_FailsOnlyWhenAllSourcesFail(t *testing.T) {
+	a := &compositeAdapter{sources: []namedAdapter{
+		{name: "vault", adapter: &fakeVaultClient{err: errors.New("vault down")}},
+		{name: "secret", adapter: &fakeVaultClient{err: errors.New("forbidden")}},
+	}}
+	if _, err := a.GetClusterInfos(); err == nil {
+		t.Fatalf("expected error when every source fails")
+	}
+}
+
+func TestNewAdapter_BuildsCompositeInOrder(t *testing.T) {
+	oldEnv := *config.Env
+	defer func() { *config.Env = oldEnv }()
+	config.Env.ClusterKubeconfig = "/tmp/kubeconfig"
+
+	got, err := NewAdapter("kubeconfig, vault, kubeconfig")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	composite, ok := got.(*compositeAdapter)
+	if !ok {
+		t.Fatalf("expected composite source, got %T", got)
+	}
+	if len(composite.sources) != 2 || composite.sources[0].name != SourceKubeconfig || composite.sources[1].name != SourceVault {
+		t.Fatalf("unexpected source order: %+v", composite.sources)
+	}
+
+	if _, err := NewAdapter("vault,consul"); err == nil {
+		t.Fatalf("expected error for unsupported source in list")
+	}
+}
@@ -0,0 +1,155 @@
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"federation-metric-api/model"
+)
+
+// namedAdapter 는 충돌 경고에 소스 이름을 남기기 위해 어댑터와 이름을 함께 보관한다.
+type namedAdapter struct {
+	name    string
+	adapter ClusterConfigAdapter
+}
+
+// compositeAdapter 는 여러 소스를 우선순위 순서로 조회해 ClusterID 기준으로 병합한다.
+// 같은 ClusterID 는 앞선 소스의 값을 사용하므로 클러스터를 소스 간에 하나씩 옮길 수 있다.
+// 조회에 실패한 소스는 마지막으로 성공한 결과를 대신 사용한다.
+type compositeAdapter struct {
+	sources []namedAdapter
+
+	mu   sync.Mutex
+	last map[string][]model.ClusterCredential
+	// warned 는 ClusterID 별로 마지막으로 경고한 충돌이다. 같은 충돌은 바뀔 때까지 다시 경고하지 않는다.
+	warned map[string]string
+}
+
+// PartialError 는 일부 소스 조회에 실패해 해당 소스의 마지막 결과로 대신 병합한 경우이다.
+// 함께 반환된 인증 정보는 사용할 수 있으며, 오류는 의존성 상태에 실패로 기록해야 한다.
+type PartialError struct {
+	Err error
+}
+
+func (e *PartialError) Error() string {
+	return "일부 클러스터 인증 정보 소스 조회 실패: " + e.Err.Error()
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// Conflict 는 같은 ClusterID 가 여러 소스에서 서로 다른 API 주소로 조회된 경우이다.
+// Source 의 값이 채택되고 IgnoredSource 의 값은 버려진다.
+type Conflict struct {
+	ClusterID     string
+	Source        string
+	URL           string
+	IgnoredSource string
+	IgnoredURL    string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s 클러스터 주소가 소스마다 다릅니다: %s=%s, %s=%s (%s 값을 사용합니다)",
+		c.ClusterID, c.Source, c.URL, c.IgnoredSource, c.IgnoredURL, c.Source)
+}
+
+func (a *compositeAdapter) GetClusterInfos() ([]model.ClusterCredential, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.last == nil {
+		a.last = map[string][]model.ClusterCredential{}
+	}
+
+	results := make([][]model.ClusterCredential, len(a.sources))
+	var errs []error
+	for i, s := range a.sources {
+		infos, err := s.adapter.GetClusterInfos()
+		if err != nil {
+			last := a.last[s.name]
+			log.Printf("%s 소스 조회 실패, 마지막으로 조회한 인증 정보 %d개를 사용합니다: %v", s.name, len(last), err)
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			results[i] = last
+			continue
+		}
+		a.last[s.name] = infos
+		results[i] = infos
+	}
+	if len(errs) == len(a.sources) {
+		return nil, errors.Join(errs...)
+	}
+
+	merged, conflicts := a.merge(results)
+	a.warnConflicts(conflicts)
+	if len(errs) > 0 {
+		return merged, &PartialError{Err: errors.Join(errs...)}
+	}
+	return merged, nil
+}
+
+// warnConflicts 는 클러스터별 충돌을 처음 발견했거나 충돌한 소스, 주소가 바뀐 경우에만 경고한다.
+// 충돌이 해소된 클러스터는 기록을 지워 다시 충돌하면 경고한다. a.mu 를 잡은 상태에서 호출한다.
+func (a *compositeAdapter) warnConflicts(conflicts []Conflict) {
+	byCluster := map[string][]string{}
+	for _, c := range conflicts {
+		byCluster[c.ClusterID] = append(byCluster[c.ClusterID], c.String())
+	}
+	if a.warned == nil {
+		a.warned = map[string]string{}
+	}
+	for id := range a.warned {
+		if _, ok := byCluster[id]; !ok {
+			delete(a.warned, id)
+		}
+	}
+	for id, messages := range byCluster {
+		key := strings.Join(messages, "\n")
+		if a.warned[id] == key {
+			continue
+		}
+		a.warned[id] = key
+		for _, message := range messages {
+			log.Printf("%s", message)
+		}
+	}
+}
+
+// merge 는 results[i] 가 sources[i] 의 결과일 때 우선순위 병합 결과와 주소 충돌 목록을 반환한다.
+func (a *compositeAdapter) merge(results [][]model.ClusterCredential) ([]model.ClusterCredential, []Conflict) {
+	type owner struct {
+		index  int
+		source string
+	}
+	owners := map[string]owner{}
+	var merged []model.ClusterCredential
+	var conflicts []Conflict
+
+	for i, infos := range results {
+		for _, info := range infos {
+			o, seen := owners[info.ClusterID]
+			if !seen {
+				owners[info.ClusterID] = owner{index: len(merged), source: a.sources[i].name}
+				merged = append(merged, info)
+				continue
+			}
+			if kept := merged[o.index]; !sameEndpoint(kept.APIServerURL, info.APIServerURL) {
+				conflicts = append(conflicts, Conflict{
+					ClusterID:     info.ClusterID,
+					Source:        o.source,
+					URL:           kept.APIServerURL,
+					IgnoredSource: a.sources[i].name,
+					IgnoredURL:    info.APIServerURL,
+				})
+			}
+		}
+	}
+	return merged, conflicts
+}
+
+func sameEndpoint(a, b string) bool {
+	trim := func(s string) string { return strings.TrimSuffix(strings.TrimSpace(s), "/") }
+	return strings.EqualFold(trim(a), trim(b))
+}
@@ -2,7 +2,13 @@ package adapter
 
 import (
 	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
 
+	"federation-metric-api/config"
+	"federation-metric-api/internal/karmada"
 	"federation-metric-api/internal/vault"
 	"federation-metric-api/model"
 )
@@ -15,13 +21,73 @@ var newVaultClient = func(cfg *vault.Config) (VaultClusterInfoClient, error) {
 	return vault.NewClient(cfg)
 }
 
+// credentialWatcher 는 변경 감시를 지원하는 Vault 클라이언트이다.
+type credentialWatcher interface {
+	WatchClusters(interval time.Duration, events chan<- model.CredentialEvent)
+}
+
+// events 는 인증 정보 소스가 감지한 변경 이벤트이다. 감시를 지원하지 않는 소스는 이벤트를 보내지 않는다.
+var events = make(chan model.CredentialEvent, 64)
+
+// Events 는 클러스터 인증 정보 변경 이벤트 채널을 반환한다.
+func Events() <-chan model.CredentialEvent {
+	return events
+}
+
+// vaultWatchInterval 은 Vault 시크릿 버전 확인 주기이다. 0 이하이면 감시하지 않고 매번 전체를 읽는다.
+// 확인할 때마다 폴더별 LIST 와 시크릿별 읽기(KV v2 는 메타데이터, KV v1 은 시크릿 전체)가 한 번씩
+// 발생하므로 수집 주기(30초)보다 길게 잡아 주기마다 전체를 읽을 때보다 요청 수를 줄인다.
+var vaultWatchInterval = defaultVaultWatchInterval
+
+const defaultVaultWatchInterval = 60 * time.Second
+
+func init() {
+	if config.Env.VaultWatchInterval != 0 {
+		vaultWatchInterval = time.Duration(config.Env.VaultWatchInterval) * time.Second
+	}
+}
+
+// vaultClient 는 수집 주기마다 로그인하지 않도록 프로세스 수명 동안 재사용하는 Vault 클라이언트이다.
+var (
+	vaultMu     sync.Mutex
+	vaultClient VaultClusterInfoClient
+)
+
 func getClusterInfosFrom(c VaultClusterInfoClient) ([]model.ClusterCredential, error) {
 	return c.GetClusterInfos()
 }
 
-func GetClusterInfos() ([]model.ClusterCredential, error) {
-	vaultCfg := vault.ConfigFromEnv()
-	vaultClient, err := newVaultClient(vaultCfg)
+func sharedVaultClient() (VaultClusterInfoClient, error) {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	if vaultClient != nil {
+		return vaultClient, nil
+	}
+	client, err := newVaultClient(vault.ConfigFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	vaultClient = client
+	if w, ok := client.(credentialWatcher); ok && vaultWatchInterval > 0 {
+		w.WatchClusters(vaultWatchInterval, events)
+	}
+	return vaultClient, nil
+}
+
+// 클러스터 인증 정보 소스 (ClusterSource 설정값)
+const (
+	SourceVault      = "vault"
+	SourceKubeconfig = "kubeconfig"
+	SourceSecret     = "secret"
+	SourceKarmada    = "karmada"
+)
+
+// vaultAdapter 는 공유 Vault 클라이언트로 인증 정보를 읽는 기본 소스이다.
+type vaultAdapter struct{}
+
+func (vaultAdapter) GetClusterInfos() ([]model.ClusterCredential, error) {
+	vaultClient, err := sharedVaultClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create vault client: %w", err)
 	}
@@ -31,3 +97,96 @@ func GetClusterInfos() ([]model.ClusterCredential, error) {
 	}
 	return clusterInfos, nil
 }
+
+// NewAdapter 는 source 설정에 해당하는 클러스터 인증 정보 소스를 만든다. 비어 있으면 Vault 를 사용한다.
+// "secret,vault" 처럼 여러 소스를 쉼표로 나열하면 앞선 소스가 우선하는 복합 소스를 만든다.
+func NewAdapter(source string) (ClusterConfigAdapter, error) {
+	var sources []namedAdapter
+	seen := map[string]bool{}
+	for _, name := range strings.Split(source, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			name = SourceVault
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		adapter, err := newSingleAdapter(name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, namedAdapter{name: name, adapter: adapter})
+	}
+	if len(sources) == 1 {
+		return sources[0].adapter, nil
+	}
+	return &compositeAdapter{sources: sources}, nil
+}
+
+func newSingleAdapter(source string) (ClusterConfigAdapter, error) {
+	switch source {
+	case SourceVault:
+		return vaultAdapter{}, nil
+	case SourceKubeconfig:
+		if config.Env.ClusterKubeconfig == "" {
+			return nil, fmt.Errorf("ClusterKubeconfig is required for the %s source", SourceKubeconfig)
+		}
+		return NewKubeconfigAdapter(config.Env.ClusterKubeconfig), nil
+	case SourceSecret:
+		if config.Env.ClusterSecretNamespace == "" {
+			return nil, fmt.Errorf("ClusterSecretNamespace is required for the %s source", SourceSecret)
+		}
+		return NewSecretAdapter(config.Env.ClusterSecretNamespace, config.Env.ClusterSecretSelector)
+	case SourceKarmada:
+		return NewKarmadaAdapter(karmada.NewClient()), nil
+	default:
+		return nil, fmt.Errorf("unsupported cluster source %q", source)
+	}
+}
+
+// source 는 ClusterSource 설정으로 선택된 인증 정보 소스이며 처음 사용할 때 만들어진다.
+var (
+	sourceMu sync.Mutex
+	source   ClusterConfigAdapter
+)
+
+func sharedSource() (ClusterConfigAdapter, error) {
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
+
+	if source != nil {
+		return source, nil
+	}
+	s, err := NewAdapter(config.Env.ClusterSource)
+	if err != nil {
+		return nil, err
+	}
+	source = s
+	return source, nil
+}
+
+func GetClusterInfos() ([]model.ClusterCredential, error) {
+	s, err := sharedSource()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cluster source: %w", err)
+	}
+	return s.GetClusterInfos()
+}
+
+// Close 는 공유 Vault 클라이언트의 토큰 갱신을 멈추고 토큰을 폐기한다.
+func Close() error {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	if vaultClient == nil {
+		return nil
+	}
+	var err error
+	if closer, ok := vaultClient.(io.Closer); ok {
+		err = closer.Close()
+	}
+	vaultClient = nil
+	return err
+}
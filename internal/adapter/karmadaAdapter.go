@@ -0,0 +1,60 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"federation-metric-api/internal/karmada"
+	"federation-metric-api/model"
+)
+
+type KarmadaSecretClient interface {
+	GetMemberClusters(ctx context.Context) ([]karmada.MemberCluster, error)
+	GetSecret(ctx context.Context, namespace, name string) (map[string][]byte, error)
+}
+
+// karmadaAdapter 는 Karmada Cluster 의 spec.secretRef 시크릿(token, caBundle)에서 인증 정보를 읽는다.
+// secretRef 가 없는 Pull 모드 멤버는 제외된다.
+type karmadaAdapter struct {
+	client KarmadaSecretClient
+}
+
+func NewKarmadaAdapter(client KarmadaSecretClient) ClusterConfigAdapter {
+	return &karmadaAdapter{client: client}
+}
+
+func (a *karmadaAdapter) GetClusterInfos() ([]model.ClusterCredential, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sourceTimeout)
+	defer cancel()
+
+	members, err := a.client.GetMemberClusters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list karmada clusters: %w", err)
+	}
+
+	var infos []model.ClusterCredential
+	for _, member := range members {
+		ref := member.SecretRef
+		if ref == nil || member.Endpoint == "" {
+			continue
+		}
+		data, err := a.client.GetSecret(ctx, ref.Namespace, ref.Name)
+		if err != nil {
+			log.Printf("%s 클러스터 시크릿(%s/%s) 조회 실패: %v", member.Name, ref.Namespace, ref.Name, err)
+			continue
+		}
+		if len(data["token"]) == 0 {
+			log.Printf("%s 클러스터 시크릿(%s/%s)에 token 이 없습니다", member.Name, ref.Namespace, ref.Name)
+			continue
+		}
+		infos = append(infos, model.ClusterCredential{
+			ClusterID:    member.Name,
+			APIServerURL: member.Endpoint,
+			BearerToken:  string(data["token"]),
+			CACert:       string(data["caBundle"]),
+			Insecure:     member.InsecureSkipTLSVerification,
+		})
+	}
+	return infos, nil
+}
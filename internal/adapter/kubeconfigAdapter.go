@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"fmt"
+	"sort"
+
+	"federation-metric-api/model"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfigAdapter 는 kubeconfig 파일의 컨텍스트마다 클러스터 하나를 반환한다.
+// ClusterID 는 컨텍스트 이름이며, 인증 정보는 해당 컨텍스트만 남긴 kubeconfig 로 전달된다.
+type kubeconfigAdapter struct {
+	path string
+}
+
+func NewKubeconfigAdapter(path string) ClusterConfigAdapter {
+	return &kubeconfigAdapter{path: path}
+}
+
+func (a *kubeconfigAdapter) GetClusterInfos() ([]model.ClusterCredential, error) {
+	cfg, err := clientcmd.LoadFromFile(a.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig %s: %w", a.path, err)
+	}
+	// 인증서 파일 경로를 데이터로 옮겨 kubeconfig 문자열만으로 접속할 수 있게 한다.
+	if err := clientcmdapi.FlattenConfig(cfg); err != nil {
+		return nil, fmt.Errorf("failed to flatten kubeconfig %s: %w", a.path, err)
+	}
+
+	names := make([]string, 0, len(cfg.Contexts))
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var infos []model.ClusterCredential
+	for _, name := range names {
+		cluster, ok := cfg.Clusters[cfg.Contexts[name].Cluster]
+		if !ok || cluster.Server == "" {
+			continue
+		}
+		single := cfg.DeepCopy()
+		single.CurrentContext = name
+		if err := clientcmdapi.MinifyConfig(single); err != nil {
+			continue
+		}
+		blob, err := clientcmd.Write(*single)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, model.ClusterCredential{
+			ClusterID:    name,
+			APIServerURL: cluster.Server,
+			Kubeconfig:   string(blob),
+			CACert:       string(cluster.CertificateAuthorityData),
+			Insecure:     cluster.InsecureSkipTLSVerify,
+		})
+	}
+	return infos, nil
+}
@@ -0,0 +1,99 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"federation-metric-api/internal/util"
+	"federation-metric-api/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ClusterNameLabel 은 시크릿 이름과 다른 ClusterID 를 지정할 때 사용하는 레이블이다.
+const ClusterNameLabel = "federation.k-paas.io/cluster-name"
+
+// 클러스터 시크릿의 데이터 키
+const (
+	secretKeyAPIURL     = "apiUrl"
+	secretKeyToken      = "token"
+	secretKeyClientCert = "clientCert"
+	secretKeyClientKey  = "clientKey"
+	secretKeyKubeconfig = "kubeconfig"
+	secretKeyCACert     = "caCert"
+	secretKeyInsecure   = "insecure"
+)
+
+// sourceTimeout 은 Kubernetes, Karmada API 에서 인증 정보를 읽을 때의 제한 시간이다.
+const sourceTimeout = 30 * time.Second
+
+// secretAdapter 는 호스트 클러스터 네임스페이스의 클러스터별 시크릿에서 인증 정보를 읽는다.
+type secretAdapter struct {
+	client    kubernetes.Interface
+	namespace string
+	selector  string
+}
+
+// NewSecretAdapter 는 in-cluster 설정으로 호스트 클러스터에 접속하는 시크릿 소스를 만든다.
+func NewSecretAdapter(namespace, selector string) (ClusterConfigAdapter, error) {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &secretAdapter{client: client, namespace: namespace, selector: selector}, nil
+}
+
+func (a *secretAdapter) GetClusterInfos() ([]model.ClusterCredential, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sourceTimeout)
+	defer cancel()
+
+	secrets, err := a.client.CoreV1().Secrets(a.namespace).List(ctx, metav1.ListOptions{LabelSelector: a.selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster secrets in %s: %w", a.namespace, err)
+	}
+
+	var infos []model.ClusterCredential
+	for _, secret := range secrets.Items {
+		id := secret.Labels[ClusterNameLabel]
+		if id == "" {
+			id = secret.Name
+		}
+		if info, ok := credentialFromSecretData(id, secret.Data); ok {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// credentialFromSecretData 는 시크릿 데이터로 ClusterCredential 을 만든다.
+// API 주소와 인증 수단(토큰, 클라이언트 인증서, kubeconfig 중 하나)이 없으면 false 를 반환한다.
+func credentialFromSecretData(id string, data map[string][]byte) (model.ClusterCredential, bool) {
+	info := model.ClusterCredential{
+		ClusterID:    id,
+		APIServerURL: string(data[secretKeyAPIURL]),
+		BearerToken:  string(data[secretKeyToken]),
+		ClientCert:   string(data[secretKeyClientCert]),
+		ClientKey:    string(data[secretKeyClientKey]),
+		Kubeconfig:   string(data[secretKeyKubeconfig]),
+		CACert:       string(data[secretKeyCACert]),
+	}
+	info.Insecure, _ = strconv.ParseBool(string(data[secretKeyInsecure]))
+
+	if info.APIServerURL == "" && info.Kubeconfig != "" {
+		info.APIServerURL = util.KubeconfigServer(info.Kubeconfig)
+	}
+	if info.APIServerURL == "" {
+		return info, false
+	}
+	if info.BearerToken == "" && (info.ClientCert == "" || info.ClientKey == "") && info.Kubeconfig == "" {
+		return info, false
+	}
+	return info, true
+}
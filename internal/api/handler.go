@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"federation-metric-api/model"
+)
+
+// SnapshotFunc 는 마지막으로 수집한 스냅샷을 반환한다. 아직 수집 전이면 false 를 반환한다.
+type SnapshotFunc func() (model.MetricStatus, bool)
+
+type handler struct {
+	snapshot SnapshotFunc
+}
+
+// Register 는 스냅샷 조회 API 를 mux 에 등록한다.
+func Register(mux *http.ServeMux, snapshot SnapshotFunc) {
+	h := &handler{snapshot: snapshot}
+	mux.HandleFunc("GET /api/v1/snapshot", h.getSnapshot)
+	mux.HandleFunc("GET /api/v1/clusters", h.listClusters)
+	mux.HandleFunc("GET /api/v1/clusters/{clusterId}", h.getCluster)
+}
+
+// getSnapshot godoc
+// @Summary      최신 페더레이션 스냅샷 조회
+// @Description  호스트·멤버 클러스터 지표, Karmada 매칭 결과, 의존성 상태를 포함한 마지막 스냅샷을 반환합니다.
+// @Tags         snapshot
+// @Produce      json
+// @Success      200  {object}  model.MetricStatus
+// @Failure      503  {object}  model.ErrorResponse  "아직 수집된 스냅샷이 없음"
+// @Router       /api/v1/snapshot [get]
+func (h *handler) getSnapshot(w http.ResponseWriter, r *http.Request) {
+	status, ok := h.snapshot()
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, "no snapshot has been collected yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// listClusters godoc
+// @Summary      클러스터 목록 조회
+// @Description  마지막 스냅샷에 포함된 호스트·멤버 클러스터와 각 클러스터의 수집 상태를 반환합니다.
+// @Tags         clusters
+// @Produce      json
+// @Success      200  {array}   model.ClusterSummary
+// @Failure      503  {object}  model.ErrorResponse  "아직 수집된 스냅샷이 없음"
+// @Router       /api/v1/clusters [get]
+func (h *handler) listClusters(w http.ResponseWriter, r *http.Request) {
+	status, ok := h.snapshot()
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, "no snapshot has been collected yet")
+		return
+	}
+	clusters := make([]model.ClusterSummary, 0, len(status.MemberClusterStatus)+1)
+	if status.HostClusterStatus.ClusterId != "" {
+		clusters = append(clusters, summarize(status.HostClusterStatus, model.ClusterRoleHost))
+	}
+	for _, member := range status.MemberClusterStatus {
+		clusters = append(clusters, summarize(member, model.ClusterRoleMember))
+	}
+	writeJSON(w, http.StatusOK, clusters)
+}
+
+// getCluster godoc
+// @Summary      클러스터 상태 조회
+// @Description  마지막 스냅샷에서 clusterId 에 해당하는 클러스터의 상태와 지표를 노드별 상태와 함께 반환합니다.
+// @Tags         clusters
+// @Produce      json
+// @Param        clusterId  path      string  true  "클러스터 ID"
+// @Success      200        {object}  model.ClusterStatus
+// @Failure      404        {object}  model.ErrorResponse  "스냅샷에 없는 클러스터"
+// @Failure      503        {object}  model.ErrorResponse  "아직 수집된 스냅샷이 없음"
+// @Router       /api/v1/clusters/{clusterId} [get]
+func (h *handler) getCluster(w http.ResponseWriter, r *http.Request) {
+	status, ok := h.snapshot()
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, "no snapshot has been collected yet")
+		return
+	}
+	id := r.PathValue("clusterId")
+	if status.HostClusterStatus.ClusterId == id && id != "" {
+		writeJSON(w, http.StatusOK, status.HostClusterStatus)
+		return
+	}
+	for _, member := range status.MemberClusterStatus {
+		if member.ClusterId == id {
+			writeJSON(w, http.StatusOK, member)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "cluster "+id+" not found")
+}
+
+func summarize(cluster model.ClusterStatus, role string) model.ClusterSummary {
+	return model.ClusterSummary{
+		ClusterId:       cluster.ClusterId,
+		Role:            role,
+		AccessMode:      cluster.AccessMode,
+		Status:          cluster.Status,
+		CollectState:    cluster.CollectState,
+		CollectError:    cluster.CollectError,
+		LastSuccessTime: cluster.LastSuccessTime,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, model.ErrorResponse{Message: message})
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"federation-metric-api/internal/history"
+	"federation-metric-api/model"
+)
+
+func newTestMux(status model.MetricStatus, ok bool) *http.ServeMux {
+	mux := http.NewServeMux()
+	Register(mux, func() (model.MetricStatus, bool) { return status, ok })
+	return mux
+}
+
+func get(t *testing.T, mux *http.ServeMux, path string, out interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("invalid JSON from %s: %v (%s)", path, err, rec.Body.String())
+		}
+	}
+	return rec.Code
+}
+
+func TestHandler_ServesLatestSnapshot(t *testing.T) {
+	last := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	mux := newTestMux(model.MetricStatus{
+		Time:              last,
+		HostClusterStatus: model.ClusterStatus{ClusterId: "host-1", CollectState: model.CollectStateOk, Status: "True", LastSuccessTime: &last},
+		MemberClusterStatus: []model.ClusterStatus{
+			{ClusterId: "member-1", AccessMode: model.AccessModeKarmadaProxy, CollectState: model.CollectStateTimeout, CollectError: "collection timed out"},
+		},
+	}, true)
+
+	var snapshot model.MetricStatus
+	if code := get(t, mux, "/api/v1/snapshot", &snapshot); code != http.StatusOK || !snapshot.Time.Equal(last) {
+		t.Fatalf("unexpected snapshot response %d: %+v", code, snapshot)
+	}
+
+	var clusters []model.ClusterSummary
+	if code := get(t, mux, "/api/v1/clusters", &clusters); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	if len(clusters) != 2 || clusters[0].Role != model.ClusterRoleHost || clusters[1].ClusterId != "member-1" ||
+		clusters[1].Role != model.ClusterRoleMember || clusters[1].CollectState != model.CollectStateTimeout {
+		t.Fatalf("unexpected cluster list: %+v", clusters)
+	}
+
+	var member model.ClusterStatus
+	if code := get(t, mux, "/api/v1/clusters/member-1", &member); code != http.StatusOK || member.AccessMode != model.AccessModeKarmadaProxy {
+		t.Fatalf("unexpected member response %d: %+v", code, member)
+	}
+	var host model.ClusterStatus
+	if code := get(t, mux, "/api/v1/clusters/host-1", &host); code != http.StatusOK || host.ClusterId != "host-1" {
+		t.Fatalf("unexpected host response %d: %+v", code, host)
+	}
+
+	var errResp model.ErrorResponse
+	if code := get(t, mux, "/api/v1/clusters/unknown", &errResp); code != http.StatusNotFound || errResp.Message == "" {
+		t.Fatalf("expected 404 for unknown cluster, got %d: %+v", code, errResp)
+	}
+}
+
+func TestHandler_UnavailableBeforeFirstSnapshot(t *testing.T) {
+	mux := newTestMux(model.MetricStatus{}, false)
+	for _, path := range []string{"/api/v1/snapshot", "/api/v1/clusters", "/api/v1/clusters/host-1"} {
+		var errResp model.ErrorResponse
+		if code := get(t, mux, path, &errResp); code != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected 503 before the first snapshot, got %d", path, code)
+		}
+	}
+}
+
+type fakeHistory struct {
+	clusterID, resolution string
+	from, to              time.Time
+	err                   error
+}
+
+func (f *fakeHistory) Query(clusterID, resolution string, from, to time.Time) (model.ClusterHistory, error) {
+	f.clusterID, f.resolution, f.from, f.to = clusterID, resolution, from, to
+	if f.err != nil {
+		return model.ClusterHistory{}, f.err
+	}
+	return model.ClusterHistory{ClusterId: clusterID, Resolution: "raw", From: from, To: to, Points: []model.UsagePoint{}}, nil
+}
+
+func TestHistoryHandler_ParsesRangeAndMapsErrors(t *testing.T) {
+	store := &fakeHistory{}
+	mux := http.NewServeMux()
+	RegisterHistory(mux, store)
+
+	var got model.ClusterHistory
+	code := get(t, mux, "/api/v1/clusters/member-1/history?from=1735689600&to=2025-01-01T01:00:00Z&resolution=5m", &got)
+	if code != http.StatusOK || store.clusterID != "member-1" || store.resolution != "5m" ||
+		!store.from.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) || !store.to.Equal(time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected query %d: %+v", code, store)
+	}
+
+	// from 을 생략하면 to 로부터 1시간 전부터 조회한다.
+	get(t, mux, "/api/v1/clusters/member-1/history?to=2025-01-01T01:00:00Z", nil)
+	if store.to.Sub(store.from) != defaultHistoryRange {
+		t.Fatalf("expected default range, got %s ~ %s", store.from, store.to)
+	}
+
+	var errResp model.ErrorResponse
+	if code := get(t, mux, "/api/v1/clusters/member-1/history?from=yesterday", &errResp); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid from, got %d", code)
+	}
+	if code := get(t, mux, "/api/v1/clusters/member-1/history?from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z", &errResp); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for reversed range, got %d", code)
+	}
+	store.err = history.ErrUnknownResolution
+	if code := get(t, mux, "/api/v1/clusters/member-1/history?resolution=10s", &errResp); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown resolution, got %d", code)
+	}
+	store.err = history.ErrUnknownCluster
+	if code := get(t, mux, "/api/v1/clusters/unknown/history", &errResp); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown cluster, got %d", code)
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"federation-metric-api/internal/history"
+	"federation-metric-api/model"
+)
+
+// defaultHistoryRange 는 from 을 지정하지 않았을 때 조회하는 기간이다.
+const defaultHistoryRange = time.Hour
+
+// HistoryQuerier 는 클러스터 사용률 이력 저장소이다.
+type HistoryQuerier interface {
+	Query(clusterID, resolution string, from, to time.Time) (model.ClusterHistory, error)
+}
+
+type historyHandler struct {
+	store HistoryQuerier
+	now   func() time.Time
+}
+
+// RegisterHistory 는 클러스터 사용률 이력 조회 API 를 mux 에 등록한다.
+func RegisterHistory(mux *http.ServeMux, store HistoryQuerier) {
+	h := &historyHandler{store: store, now: time.Now}
+	mux.HandleFunc("GET /api/v1/clusters/{clusterId}/history", h.getHistory)
+}
+
+// getHistory godoc
+// @Summary      클러스터 사용률 이력 조회
+// @Description  from, to 사이의 CPU/메모리 실사용률과 요청률 시계열을 반환합니다. 원본(raw)은 30초, 5m·1h 는 구간 평균입니다.
+// @Description  resolution 을 생략하면 from 시점까지 보관하는 가장 세밀한 해상도를 사용합니다.
+// @Description  이력은 수집기 파드가 보관하며 기본 보관 기간은 raw 6시간, 5m 2일, 1h 7일입니다(HISTORY_RETENTION).
+// @Description  HISTORY_PATH 파일에 주기적으로 저장해 재시작 후 복원하며, 파일이 없어지면 그 이전 이력은 조회되지 않습니다.
+// @Tags         clusters
+// @Produce      json
+// @Param        clusterId   path      string  true   "클러스터 ID"
+// @Param        from        query     string  false  "시작 시각 (RFC3339 또는 Unix 초, 기본값 to 1시간 전)"
+// @Param        to          query     string  false  "종료 시각 (RFC3339 또는 Unix 초, 기본값 현재)"
+// @Param        resolution  query     string  false  "해상도"  Enums(raw, 5m, 1h)
+// @Success      200         {object}  model.ClusterHistory
+// @Failure      400         {object}  model.ErrorResponse  "잘못된 조회 조건"
+// @Failure      404         {object}  model.ErrorResponse  "이력이 없는 클러스터"
+// @Router       /api/v1/clusters/{clusterId}/history [get]
+func (h *historyHandler) getHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	to := h.now().UTC()
+	if v := query.Get("to"); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid to: "+err.Error())
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultHistoryRange)
+	if v := query.Get("from"); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid from: "+err.Error())
+			return
+		}
+		from = t
+	}
+	if from.After(to) {
+		writeError(w, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+
+	result, err := h.store.Query(r.PathValue("clusterId"), query.Get("resolution"), from, to)
+	switch {
+	case errors.Is(err, history.ErrUnknownResolution):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, history.ErrUnknownCluster):
+		writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// parseTime 은 RFC3339 또는 Unix 초 형식의 시각을 읽는다.
+func parseTime(v string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
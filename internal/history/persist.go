@@ -0,0 +1,85 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"federation-metric-api/model"
+)
+
+// snapshotFile 은 파일에 저장하는 이력이다. 해상도는 이름으로 저장해 보관 기간 설정이 바뀌어도 다시 읽을 수 있다.
+type snapshotFile struct {
+	Time     time.Time                          `json:"time"`
+	Clusters map[string]map[string]snapshotTier `json:"clusters"`
+}
+
+type snapshotTier struct {
+	Points []model.UsagePoint `json:"points"`
+	Open   *model.UsagePoint  `json:"open,omitempty"`
+}
+
+// Save 는 이력 전체를 path 에 저장한다. 쓰는 도중 종료되어도 이전 파일이 남도록 임시 파일에 쓴 뒤 이름을 바꾼다.
+func (s *Store) Save(path string) error {
+	s.mu.RLock()
+	snapshot := snapshotFile{Time: s.now().UTC(), Clusters: make(map[string]map[string]snapshotTier, len(s.clusters))}
+	for id, tiers := range s.clusters {
+		byName := make(map[string]snapshotTier, len(tiers))
+		for i, res := range s.resolutions {
+			byName[res.Name] = snapshotTier{Points: tiers[i].points, Open: tiers[i].open}
+		}
+		snapshot.Clusters[id] = byName
+	}
+	data, err := json.Marshal(snapshot)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load 는 Save 로 저장한 이력을 읽어 현재 이력을 바꾼다. 파일이 없으면 아무것도 하지 않는다.
+// 설정에 없는 해상도는 버리고, 보관 기간이 지난 값은 현재 시각 기준으로 정리한다.
+func (s *Store) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var snapshot snapshotFile
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("invalid history file %s: %w", path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clusters = make(map[string][]*tier, len(snapshot.Clusters))
+	for id, byName := range snapshot.Clusters {
+		tiers := make([]*tier, len(s.resolutions))
+		for i, res := range s.resolutions {
+			saved := byName[res.Name]
+			tiers[i] = &tier{points: saved.Points, open: saved.Open}
+		}
+		s.clusters[id] = tiers
+	}
+	s.prune(s.now())
+	return nil
+}
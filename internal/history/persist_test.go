@@ -0,0 +1,60 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"federation-metric-api/model"
+)
+
+func TestStore_SaveAndLoadKeepsRollups(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	s := NewStore(DefaultResolutions)
+	s.now = func() time.Time { return base.Add(10 * time.Minute) }
+	s.Record(snapshot(base, 10, model.CollectStateOk))
+	s.Record(snapshot(base.Add(30*time.Second), 20, model.CollectStateOk))
+	s.Record(snapshot(base.Add(5*time.Minute), 40, model.CollectStateOk))
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// 재시작 후 원본 보관 기간은 지났지만 5분 평균은 남아 있다.
+	restarted := NewStore(DefaultResolutions)
+	restarted.now = func() time.Time { return base.Add(7 * time.Hour) }
+	if err := restarted.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := restarted.Query("host", "raw", base, base.Add(time.Hour)); len(raw.Points) != 0 {
+		t.Fatalf("expected expired raw points to be pruned on load, got %+v", raw.Points)
+	}
+	rollup, err := restarted.Query("host", "5m", base, base.Add(time.Hour))
+	if err != nil || len(rollup.Points) != 2 || rollup.Points[0].RealTimeUsage.Cpu != 15 {
+		t.Fatalf("expected 5m rollups restored, got %+v (%v)", rollup.Points, err)
+	}
+
+	// 열린 구간에 이어서 기록된다.
+	restarted.Record(snapshot(base.Add(6*time.Minute), 20, model.CollectStateOk))
+	rollup, _ = restarted.Query("host", "5m", base, base.Add(time.Hour))
+	if p := rollup.Points[1]; p.Samples != 2 || p.RealTimeUsage.Cpu != 30 {
+		t.Fatalf("expected the restored open bucket to keep averaging, got %+v", p)
+	}
+}
+
+func TestStore_LoadMissingOrInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(DefaultResolutions)
+	if err := s.Load(filepath.Join(dir, "missing.json")); err != nil {
+		t.Fatalf("expected a missing file to be ignored, got %v", err)
+	}
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Load(bad); err == nil {
+		t.Fatal("expected an error for an invalid history file")
+	}
+}
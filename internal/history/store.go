@@ -0,0 +1,230 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"federation-metric-api/model"
+)
+
+var (
+	ErrUnknownCluster    = errors.New("no history for cluster")
+	ErrUnknownResolution = errors.New("unknown resolution")
+)
+
+// Resolution 은 이력 해상도와 보관 기간이다. Step 이 0 이면 수집 주기(30초)마다의 원본 값을 보관한다.
+type Resolution struct {
+	Name      string
+	Step      time.Duration
+	Retention time.Duration
+}
+
+// DefaultResolutions 는 원본 30초 값을 6시간, 5분 평균을 2일, 1시간 평균을 7일 보관한다.
+// 이력은 수집기 파드가 보관하므로 기본값은 파드 하나가 유지할 수 있는 기간으로 잡았다.
+// 더 긴 기간은 Save/Load 로 이력을 영구 볼륨에 저장할 때 ParseRetention 으로 늘린다.
+var DefaultResolutions = []Resolution{
+	{Name: "raw", Retention: 6 * time.Hour},
+	{Name: "5m", Step: 5 * time.Minute, Retention: 2 * 24 * time.Hour},
+	{Name: "1h", Step: time.Hour, Retention: 7 * 24 * time.Hour},
+}
+
+// ParseRetention 은 "raw=6h,5m=48h,1h=168h" 형식으로 DefaultResolutions 의 보관 기간을 바꾼다.
+// 지정하지 않은 해상도는 기본값을 사용한다.
+func ParseRetention(s string) ([]Resolution, error) {
+	resolutions := append([]Resolution(nil), DefaultResolutions...)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid history retention %q, expected name=duration", pair)
+		}
+		retention, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || retention <= 0 {
+			return nil, fmt.Errorf("invalid history retention %q", pair)
+		}
+		found := false
+		for i := range resolutions {
+			if resolutions[i].Name == strings.TrimSpace(name) {
+				resolutions[i].Retention = retention
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w %q in history retention", ErrUnknownResolution, name)
+		}
+	}
+	return resolutions, nil
+}
+
+// Store 는 클러스터별 CPU/메모리 사용률 이력을 해상도별로 보관하는 메모리 시계열 저장소이다.
+// 집계 해상도는 구간이 끝날 때 평균을 확정하며, 진행 중인 구간도 조회 결과에 포함된다.
+// 메모리에 보관하므로 Save 로 저장해 두지 않으면 재시작할 때 이력이 초기화된다.
+type Store struct {
+	mu          sync.RWMutex
+	resolutions []Resolution
+	clusters    map[string][]*tier
+
+	// now 는 자동 해상도 선택 기준 시각이다. 테스트에서 교체한다.
+	now func() time.Time
+}
+
+type tier struct {
+	points []model.UsagePoint
+	open   *model.UsagePoint
+}
+
+func NewStore(resolutions []Resolution) *Store {
+	return &Store{
+		resolutions: resolutions,
+		clusters:    map[string][]*tier{},
+		now:         time.Now,
+	}
+}
+
+// Record 는 스냅샷에서 수집에 성공한 클러스터의 사용률을 추가하고 보관 기간이 지난 값을 버린다.
+// 수집에 실패한 클러스터는 사용률이 0 으로 기록되어 그래프를 왜곡하지 않도록 건너뛴다.
+func (s *Store) Record(status model.MetricStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clusters := append([]model.ClusterStatus{status.HostClusterStatus}, status.MemberClusterStatus...)
+	for _, cluster := range clusters {
+		if cluster.ClusterId == "" || cluster.CollectState != model.CollectStateOk {
+			continue
+		}
+		s.add(cluster.ClusterId, model.UsagePoint{
+			Time:          status.Time,
+			RealTimeUsage: cluster.RealTimeUsage,
+			RequestUsage:  cluster.RequestUsage,
+			Samples:       1,
+		})
+	}
+	s.prune(status.Time)
+}
+
+func (s *Store) add(clusterID string, p model.UsagePoint) {
+	tiers, ok := s.clusters[clusterID]
+	if !ok {
+		tiers = make([]*tier, len(s.resolutions))
+		for i := range tiers {
+			tiers[i] = &tier{}
+		}
+		s.clusters[clusterID] = tiers
+	}
+	for i, res := range s.resolutions {
+		t := tiers[i]
+		if res.Step <= 0 {
+			if n := len(t.points); n > 0 && !p.Time.After(t.points[n-1].Time) {
+				continue
+			}
+			t.points = append(t.points, p)
+			continue
+		}
+		start := p.Time.Truncate(res.Step)
+		if t.open != nil && !t.open.Time.Equal(start) {
+			if start.Before(t.open.Time) {
+				continue
+			}
+			t.points = append(t.points, *t.open)
+			t.open = nil
+		}
+		if t.open == nil {
+			t.open = &model.UsagePoint{Time: start}
+		}
+		t.open.RealTimeUsage = weighted(t.open.RealTimeUsage, t.open.Samples, p.RealTimeUsage)
+		t.open.RequestUsage = weighted(t.open.RequestUsage, t.open.Samples, p.RequestUsage)
+		t.open.Samples++
+	}
+}
+
+// weighted 는 n 개 값의 평균 avg 에 v 를 더한 평균을 반환한다.
+func weighted(avg model.NodeUsageFloat, n int, v model.NodeUsageFloat) model.NodeUsageFloat {
+	return model.NodeUsageFloat{
+		Cpu:    (avg.Cpu*float64(n) + v.Cpu) / float64(n+1),
+		Memory: (avg.Memory*float64(n) + v.Memory) / float64(n+1),
+	}
+}
+
+// prune 은 now 기준 보관 기간이 지난 값을 버리고, 남은 값이 없는 클러스터를 지운다.
+func (s *Store) prune(now time.Time) {
+	for id, tiers := range s.clusters {
+		empty := true
+		for i, res := range s.resolutions {
+			t := tiers[i]
+			cutoff := now.Add(-res.Retention)
+			keep := sort.Search(len(t.points), func(j int) bool { return !t.points[j].Time.Before(cutoff) })
+			t.points = append(t.points[:0], t.points[keep:]...)
+			if t.open != nil && t.open.Time.Add(res.Step).Before(cutoff) {
+				t.open = nil
+			}
+			if len(t.points) > 0 || t.open != nil {
+				empty = false
+			}
+		}
+		if empty {
+			delete(s.clusters, id)
+		}
+	}
+}
+
+// Query 는 clusterID 의 [from, to] 구간 이력을 반환한다. resolution 이 비어 있으면 from 까지의
+// 기간을 보관하는 가장 세밀한 해상도를 고른다.
+func (s *Store) Query(clusterID, resolution string, from, to time.Time) (model.ClusterHistory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx, err := s.resolve(resolution, from)
+	if err != nil {
+		return model.ClusterHistory{}, err
+	}
+	tiers, ok := s.clusters[clusterID]
+	if !ok {
+		return model.ClusterHistory{}, fmt.Errorf("%w %s", ErrUnknownCluster, clusterID)
+	}
+
+	t := tiers[idx]
+	points := []model.UsagePoint{}
+	for _, p := range t.points {
+		if !p.Time.Before(from) && !p.Time.After(to) {
+			points = append(points, p)
+		}
+	}
+	if t.open != nil && !t.open.Time.Before(from) && !t.open.Time.After(to) {
+		points = append(points, *t.open)
+	}
+	return model.ClusterHistory{
+		ClusterId:  clusterID,
+		Resolution: s.resolutions[idx].Name,
+		From:       from,
+		To:         to,
+		Points:     points,
+	}, nil
+}
+
+func (s *Store) resolve(name string, from time.Time) (int, error) {
+	if name != "" {
+		for i, res := range s.resolutions {
+			if res.Name == name {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("%w %q", ErrUnknownResolution, name)
+	}
+	if len(s.resolutions) == 0 {
+		return 0, ErrUnknownResolution
+	}
+	age := s.now().Sub(from)
+	for i, res := range s.resolutions {
+		if res.Retention >= age {
+			return i, nil
+		}
+	}
+	return len(s.resolutions) - 1, nil
+}
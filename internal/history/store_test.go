@@ -0,0 +1,123 @@
+package history
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"federation-metric-api/model"
+)
+
+func snapshot(at time.Time, cpu float64, state string) model.MetricStatus {
+	return model.MetricStatus{
+		Time: at,
+		HostClusterStatus: model.ClusterStatus{
+			ClusterId:     "host",
+			CollectState:  state,
+			RealTimeUsage: model.NodeUsageFloat{Cpu: cpu, Memory: cpu * 2},
+		},
+	}
+}
+
+func TestStore_DownsamplesIntoRollups(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewStore(DefaultResolutions)
+	s.now = func() time.Time { return base.Add(time.Hour) }
+
+	// 0~5분 구간에 10, 20, 5~10분 구간에 40, 실패 값은 기록하지 않는다.
+	s.Record(snapshot(base, 10, model.CollectStateOk))
+	s.Record(snapshot(base.Add(30*time.Second), 20, model.CollectStateOk))
+	s.Record(snapshot(base.Add(time.Minute), 0, model.CollectStateUnreachable))
+	s.Record(snapshot(base.Add(5*time.Minute), 40, model.CollectStateOk))
+
+	raw, err := s.Query("host", "raw", base, base.Add(time.Hour))
+	if err != nil || len(raw.Points) != 3 {
+		t.Fatalf("expected 3 raw points, got %+v (%v)", raw.Points, err)
+	}
+
+	rollup, err := s.Query("host", "5m", base, base.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollup.Points) != 2 {
+		t.Fatalf("expected a closed and an open 5m bucket, got %+v", rollup.Points)
+	}
+	first := rollup.Points[0]
+	if !first.Time.Equal(base) || first.Samples != 2 || first.RealTimeUsage.Cpu != 15 || first.RealTimeUsage.Memory != 30 {
+		t.Fatalf("unexpected first bucket: %+v", first)
+	}
+	if rollup.Points[1].RealTimeUsage.Cpu != 40 || rollup.Points[1].Samples != 1 {
+		t.Fatalf("unexpected open bucket: %+v", rollup.Points[1])
+	}
+
+	hourly, _ := s.Query("host", "1h", base, base.Add(time.Hour))
+	if len(hourly.Points) != 1 || hourly.Points[0].Samples != 3 || hourly.Points[0].RealTimeUsage.Cpu != 70.0/3 {
+		t.Fatalf("unexpected hourly rollup: %+v", hourly.Points)
+	}
+}
+
+func TestStore_QueryRangeAndResolution(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewStore(DefaultResolutions)
+	for i := 0; i < 10; i++ {
+		s.Record(snapshot(base.Add(time.Duration(i)*30*time.Second), float64(i), model.CollectStateOk))
+	}
+
+	s.now = func() time.Time { return base.Add(5 * time.Minute) }
+	got, err := s.Query("host", "", base.Add(time.Minute), base.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Resolution != "raw" || len(got.Points) != 3 || got.Points[0].RealTimeUsage.Cpu != 2 {
+		t.Fatalf("unexpected raw range: %+v", got)
+	}
+
+	// 원본 보관 기간보다 오래된 구간은 5분 해상도로 조회한다.
+	s.now = func() time.Time { return base.Add(48 * time.Hour) }
+	if got, _ := s.Query("host", "", base, base.Add(time.Hour)); got.Resolution != "5m" {
+		t.Fatalf("expected 5m resolution for old range, got %s", got.Resolution)
+	}
+
+	if _, err := s.Query("host", "10s", base, base); !errors.Is(err, ErrUnknownResolution) {
+		t.Fatalf("expected ErrUnknownResolution, got %v", err)
+	}
+	if _, err := s.Query("missing", "", base, base); !errors.Is(err, ErrUnknownCluster) {
+		t.Fatalf("expected ErrUnknownCluster, got %v", err)
+	}
+}
+
+func TestStore_PrunesExpiredPoints(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewStore([]Resolution{{Name: "raw", Retention: time.Minute}})
+	s.Record(snapshot(base, 1, model.CollectStateOk))
+	s.Record(snapshot(base.Add(30*time.Second), 2, model.CollectStateOk))
+	s.Record(model.MetricStatus{Time: base.Add(90 * time.Second)})
+
+	got, _ := s.Query("host", "raw", base, base.Add(time.Hour))
+	if len(got.Points) != 1 || got.Points[0].RealTimeUsage.Cpu != 2 {
+		t.Fatalf("expected only the unexpired point, got %+v", got.Points)
+	}
+
+	s.Record(model.MetricStatus{Time: base.Add(time.Hour)})
+	if _, err := s.Query("host", "raw", base, base.Add(time.Hour)); !errors.Is(err, ErrUnknownCluster) {
+		t.Fatalf("expected cluster to be dropped once all points expired, got %v", err)
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	res, err := ParseRetention("raw=12h, 1h=720h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Retention != 12*time.Hour || res[1].Retention != DefaultResolutions[1].Retention || res[2].Retention != 720*time.Hour {
+		t.Fatalf("unexpected resolutions: %+v", res)
+	}
+	if DefaultResolutions[0].Retention != 6*time.Hour {
+		t.Fatal("ParseRetention must not modify the defaults")
+	}
+	for _, bad := range []string{"raw", "raw=abc", "10s=1h", "raw=-1h"} {
+		if _, err := ParseRetention(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
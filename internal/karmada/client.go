@@ -3,11 +3,16 @@ package karmada
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"federation-metric-api/config"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"strings"
+
+	"k8s.io/client-go/rest"
 )
 
 type Client struct {
@@ -16,65 +21,245 @@ type Client struct {
 	client *http.Client
 }
 
+// TLSConfig 는 Karmada API 서버 연결용 tls.Config 를 만든다. caCert 가 있으면 해당 CA 로 검증하고,
+// insecure 가 true 인 경우에만 인증서 검증을 생략한다.
+func TLSConfig(caCert string, insecure bool) (*tls.Config, error) {
+	if insecure {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+	cfg := &tls.Config{}
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return cfg, fmt.Errorf("karmada CA 인증서 파싱 실패")
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// newTransport 는 TLSConfig 로 인증서 검증 방식을 정한 Karmada API 서버용 Transport 이다.
+// 직접 조회 클라이언트와 Cluster 캐시가 같은 설정을 사용한다.
+func newTransport(caCert string, insecure bool) *http.Transport {
+	tlsCfg, err := TLSConfig(caCert, insecure)
+	if err != nil {
+		log.Printf("%v, 시스템 루트 인증서로 검증합니다", err)
+	}
+	return &http.Transport{
+		TLSClientConfig: tlsCfg,
+	}
+}
+
+// restConfig 는 client-go 클라이언트가 newTransport 와 같은 TLS 설정으로 Karmada API 서버에 접속하는 rest.Config 이다.
+func restConfig(api, token, caCert string, insecure bool) *rest.Config {
+	return &rest.Config{
+		Host:        api,
+		BearerToken: token,
+		Transport:   newTransport(caCert, insecure),
+	}
+}
+
 func NewClient() *Client {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true, // 사설 인증서 무시
-		},
+	if config.Env.KarmadaInsecure {
+		log.Printf("Karmada API 인증서 검증이 비활성화되었습니다 (KarmadaInsecure=true)")
 	}
 	return &Client{
 		api:    config.Env.KarmadaApi,
 		token:  config.Env.KarmadaToken,
-		client: &http.Client{Transport: tr},
+		client: &http.Client{Transport: newTransport(config.Env.KarmadaCaCert, config.Env.KarmadaInsecure)},
 	}
 }
 
+// 멤버 클러스터 동기화 모드
+const (
+	SyncModePush = "Push"
+	SyncModePull = "Pull"
+)
+
 type MemberCluster struct {
-	Name     string `json:"name"`
-	Endpoint string `json:"endpoint"`
+	Name              string          `json:"name"`
+	Endpoint          string          `json:"endpoint"`
+	SyncMode          string          `json:"syncMode"`
+	KubernetesVersion string          `json:"kubernetesVersion"`
+	Conditions        []Condition     `json:"conditions"`
+	ResourceSummary   ResourceSummary `json:"resourceSummary"`
+	// SecretRef 는 Push 모드 멤버의 접근 토큰과 CA 가 담긴 Karmada 컨트롤 플레인 시크릿이다.
+	SecretRef                   *SecretRef `json:"secretRef,omitempty"`
+	InsecureSkipTLSVerification bool       `json:"insecureSkipTLSVerification,omitempty"`
 }
 
-func (c *Client) GetMemberClusters(ctx context.Context) ([]MemberCluster, error) {
-	url := fmt.Sprintf("%s/apis/cluster.karmada.io/v1alpha1/clusters", c.api)
+type SecretRef struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type Condition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason"`
+	Message            string `json:"message"`
+	LastTransitionTime string `json:"lastTransitionTime"`
+}
+
+// ResourceSummary 는 Karmada 가 집계한 멤버 클러스터의 리소스 요약이며 값은 Kubernetes quantity 문자열이다.
+type ResourceSummary struct {
+	Allocatable map[string]string `json:"allocatable,omitempty"`
+	Allocating  map[string]string `json:"allocating,omitempty"`
+	Allocated   map[string]string `json:"allocated,omitempty"`
+}
+
+// ReadyCondition 은 Ready 조건을 반환한다. 조건이 없으면 false 를 반환한다.
+func (m MemberCluster) ReadyCondition() (Condition, bool) {
+	for _, c := range m.Conditions {
+		if c.Type == "Ready" {
+			return c, true
+		}
+	}
+	return Condition{}, false
+}
+
+// ClusterProxyURL 은 Karmada aggregated API 를 통해 멤버 클러스터 API 서버에 접근하는 주소이다.
+func ClusterProxyURL(api, name string) string {
+	return fmt.Sprintf("%s/apis/cluster.karmada.io/v1alpha1/clusters/%s/proxy", strings.TrimRight(api, "/"), name)
+}
+
+// get 은 Karmada API 서버에 GET 요청을 보내고 JSON 응답을 out 에 디코딩한다.
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	url := fmt.Sprintf("%s%s", c.api, path)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+c.token)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("karmada 요청 실패: %w", err)
+		return fmt.Errorf("karmada 요청 실패: %w", err)
 	}
 	defer resp.Body.Close()
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != 200 {
-		//body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("karmada 응답 오류: %d - %s", resp.StatusCode, string(body))
+		return fmt.Errorf("karmada 응답 오류: %d - %s", resp.StatusCode, string(body))
 	}
+	return json.Unmarshal(body, out)
+}
+
+// clusterObject 는 Karmada Cluster 리소스 중 수집기가 사용하는 필드이다.
+type clusterObject struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		APIEndpoint                 string     `json:"apiEndpoint"`
+		SyncMode                    string     `json:"syncMode"`
+		SecretRef                   *SecretRef `json:"secretRef"`
+		InsecureSkipTLSVerification bool       `json:"insecureSkipTLSVerification"`
+	} `json:"spec"`
+	Status struct {
+		KubernetesVersion string          `json:"kubernetesVersion"`
+		Conditions        []Condition     `json:"conditions"`
+		ResourceSummary   ResourceSummary `json:"resourceSummary"`
+	} `json:"status"`
+}
+
+func (o clusterObject) member() MemberCluster {
+	return MemberCluster{
+		Name:                        o.Metadata.Name,
+		Endpoint:                    o.Spec.APIEndpoint,
+		SyncMode:                    o.Spec.SyncMode,
+		KubernetesVersion:           o.Status.KubernetesVersion,
+		Conditions:                  o.Status.Conditions,
+		ResourceSummary:             o.Status.ResourceSummary,
+		SecretRef:                   o.Spec.SecretRef,
+		InsecureSkipTLSVerification: o.Spec.InsecureSkipTLSVerification,
+	}
+}
 
+func (c *Client) GetMemberClusters(ctx context.Context) ([]MemberCluster, error) {
 	var result struct {
-		Items []struct {
-			Metadata struct {
-				Name string `json:"name"`
-			} `json:"metadata"`
-			Spec struct {
-				APIEndpoint string `json:"apiEndpoint"`
-			} `json:"spec"`
-		} `json:"items"`
-	}
-	if err := json.Unmarshal(body, &result); err != nil {
+		Items []clusterObject `json:"items"`
+	}
+	if err := c.get(ctx, "/apis/cluster.karmada.io/v1alpha1/clusters", &result); err != nil {
 		return nil, err
 	}
 
 	clusters := make([]MemberCluster, 0)
 	for _, item := range result.Items {
-		clusters = append(clusters, MemberCluster{
-			Name:     item.Metadata.Name,
-			Endpoint: item.Spec.APIEndpoint,
-		})
+		clusters = append(clusters, item.member())
 	}
 	return clusters, nil
 
 }
+
+// GetSecret 은 Karmada 컨트롤 플레인의 시크릿 데이터를 반환한다. 값은 base64 디코딩된 상태이다.
+func (c *Client) GetSecret(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+	var result struct {
+		Data map[string][]byte `json:"data"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", namespace, name), &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// ResourceBinding 은 PropagationPolicy 로 전파된 리소스와 멤버 클러스터별 배치 결과이다.
+// ClusterResourceBinding 은 Namespace 가 비어 있다.
+type ResourceBinding struct {
+	Namespace string          `json:"namespace,omitempty"`
+	Name      string          `json:"name"`
+	Resource  ObjectReference `json:"resource"`
+	Replicas  int32           `json:"replicas"`
+	Clusters  []TargetCluster `json:"clusters"`
+}
+
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+type TargetCluster struct {
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas,omitempty"`
+}
+
+// bindingObject 는 ResourceBinding, ClusterResourceBinding 리소스 중 수집기가 사용하는 필드이다.
+type bindingObject struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		Resource ObjectReference `json:"resource"`
+		Replicas int32           `json:"replicas"`
+		Clusters []TargetCluster `json:"clusters"`
+	} `json:"spec"`
+}
+
+// GetResourceBindings 는 ResourceBinding 과 ClusterResourceBinding 을 모두 조회한다.
+func (c *Client) GetResourceBindings(ctx context.Context) ([]ResourceBinding, error) {
+	var bindings []ResourceBinding
+	for _, path := range []string{
+		"/apis/work.karmada.io/v1alpha2/resourcebindings",
+		"/apis/work.karmada.io/v1alpha2/clusterresourcebindings",
+	} {
+		var result struct {
+			Items []bindingObject `json:"items"`
+		}
+		if err := c.get(ctx, path, &result); err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			bindings = append(bindings, ResourceBinding{
+				Namespace: item.Metadata.Namespace,
+				Name:      item.Metadata.Name,
+				Resource:  item.Spec.Resource,
+				Replicas:  item.Spec.Replicas,
+				Clusters:  item.Spec.Clusters,
+			})
+		}
+	}
+	return bindings, nil
+}
@@ -2,9 +2,19 @@ package karmada
 
 import (
 	"context"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
 )
 
 func assertEqual[T comparable](t *testing.T, name string, got, want T) {
@@ -32,11 +42,25 @@ func TestGetMemberClusters_Success(t *testing.T) {
   "items": [
     {
       "metadata": { "name": "member-1" },
-      "spec": { "apiEndpoint": "https://member1.example.com" }
+      "spec": {
+        "apiEndpoint": "https://member1.example.com",
+        "secretRef": { "namespace": "karmada-cluster", "name": "member-1" },
+        "insecureSkipTLSVerification": true
+      }
     },
     {
       "metadata": { "name": "member-2" },
-      "spec": { "apiEndpoint": "https://member2.example.com" }
+      "spec": { "apiEndpoint": "https://member2.example.com", "syncMode": "Pull" },
+      "status": {
+        "kubernetesVersion": "v1.31.2",
+        "conditions": [
+          { "type": "Ready", "status": "True", "reason": "ClusterReady", "message": "cluster is healthy and ready to accept workloads" }
+        ],
+        "resourceSummary": {
+          "allocatable": { "cpu": "8", "memory": "32Gi" },
+          "allocated": { "cpu": "2500m", "memory": "6Gi" }
+        }
+      }
     }
   ]
 }`))
@@ -61,8 +85,22 @@ func TestGetMemberClusters_Success(t *testing.T) {
 
 	assertEqual(t, "clusters[0].Name", clusters[0].Name, "member-1")
 	assertEqual(t, "clusters[0].Endpoint", clusters[0].Endpoint, "https://member1.example.com")
+	assertEqual(t, "clusters[0].SecretRef.Name", clusters[0].SecretRef.Name, "member-1")
+	assertEqual(t, "clusters[0].InsecureSkipTLSVerification", clusters[0].InsecureSkipTLSVerification, true)
+	assertEqual(t, "clusters[1].SecretRef == nil", clusters[1].SecretRef == nil, true)
 	assertEqual(t, "clusters[1].Name", clusters[1].Name, "member-2")
 	assertEqual(t, "clusters[1].Endpoint", clusters[1].Endpoint, "https://member2.example.com")
+	assertEqual(t, "clusters[1].SyncMode", clusters[1].SyncMode, SyncModePull)
+	assertEqual(t, "clusters[1].KubernetesVersion", clusters[1].KubernetesVersion, "v1.31.2")
+	assertEqual(t, "clusters[1].ResourceSummary.Allocatable[cpu]", clusters[1].ResourceSummary.Allocatable["cpu"], "8")
+	assertEqual(t, "clusters[1].ResourceSummary.Allocated[memory]", clusters[1].ResourceSummary.Allocated["memory"], "6Gi")
+
+	ready, ok := clusters[1].ReadyCondition()
+	assertEqual(t, "clusters[1] has Ready", ok, true)
+	assertEqual(t, "clusters[1] Ready.Status", ready.Status, "True")
+
+	_, ok = clusters[0].ReadyCondition()
+	assertEqual(t, "clusters[0] has Ready", ok, false)
 }
 
 func TestGetMemberClusters_Non200Status(t *testing.T) {
@@ -82,3 +120,234 @@ func TestGetMemberClusters_Non200Status(t *testing.T) {
 		t.Fatalf("expected error for non-200 response, got nil")
 	}
 }
+
+func TestGetSecret_DecodesData(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/karmada-cluster/secrets/member-1" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		// "token-1", "ca" 의 base64 값
+		_, _ = w.Write([]byte(`{"data": {"token": "dG9rZW4tMQ==", "caBundle": "Y2E="}}`))
+	}))
+	defer ts.Close()
+
+	c := &Client{api: ts.URL, token: "test-token", client: ts.Client()}
+	data, err := c.GetSecret(context.Background(), "karmada-cluster", "member-1")
+	if err != nil {
+		t.Fatalf("GetSecret returned error: %v", err)
+	}
+	assertEqual(t, "token", string(data["token"]), "token-1")
+	assertEqual(t, "caBundle", string(data["caBundle"]), "ca")
+}
+
+func TestGetResourceBindings_ListsNamespacedAndClusterBindings(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/work.karmada.io/v1alpha2/resourcebindings":
+			_, _ = w.Write([]byte(`{"items": [{
+				"metadata": {"name": "web-deployment", "namespace": "shop"},
+				"spec": {
+					"resource": {"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "shop", "name": "web"},
+					"replicas": 3,
+					"clusters": [{"name": "member-1", "replicas": 2}, {"name": "member-2", "replicas": 1}]
+				}
+			}]}`))
+		case "/apis/work.karmada.io/v1alpha2/clusterresourcebindings":
+			_, _ = w.Write([]byte(`{"items": [{
+				"metadata": {"name": "shop-namespace"},
+				"spec": {
+					"resource": {"apiVersion": "v1", "kind": "Namespace", "name": "shop"},
+					"clusters": [{"name": "member-1"}]
+				}
+			}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := &Client{api: ts.URL, token: "test-token", client: ts.Client()}
+	bindings, err := c.GetResourceBindings(context.Background())
+	if err != nil {
+		t.Fatalf("GetResourceBindings returned error: %v", err)
+	}
+	assertEqual(t, "len(bindings)", len(bindings), 2)
+	web := bindings[0]
+	assertEqual(t, "binding namespace", web.Namespace, "shop")
+	assertEqual(t, "resource kind", web.Resource.Kind, "Deployment")
+	assertEqual(t, "resource name", web.Resource.Name, "web")
+	assertEqual(t, "replicas", web.Replicas, int32(3))
+	assertEqual(t, "len(clusters)", len(web.Clusters), 2)
+	assertEqual(t, "member-1 replicas", web.Clusters[0].Replicas, int32(2))
+	assertEqual(t, "cluster binding namespace", bindings[1].Namespace, "")
+	assertEqual(t, "cluster binding kind", bindings[1].Resource.Kind, "Namespace")
+}
+
+func TestTLSConfig_VerifiesWithCABundle(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"items": []}`))
+	}))
+	defer ts.Close()
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+
+	newClient := func(caCert string, insecure bool) *Client {
+		tlsCfg, err := TLSConfig(caCert, insecure)
+		if err != nil {
+			t.Fatalf("TLSConfig returned error: %v", err)
+		}
+		return &Client{api: ts.URL, client: &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}}
+	}
+
+	if _, err := newClient(caPEM, false).GetMemberClusters(context.Background()); err != nil {
+		t.Fatalf("expected verified connection with CA bundle, got %v", err)
+	}
+	if _, err := newClient("", false).GetMemberClusters(context.Background()); err == nil {
+		t.Fatalf("expected verification failure without CA bundle")
+	}
+	if _, err := newClient("", true).GetMemberClusters(context.Background()); err != nil {
+		t.Fatalf("expected insecure opt-in to skip verification, got %v", err)
+	}
+}
+
+func TestTLSConfig_InvalidCABundle(t *testing.T) {
+	if _, err := TLSConfig("not a pem", false); err == nil {
+		t.Fatalf("expected error for invalid CA bundle")
+	}
+}
+
+func TestRestConfig_SharesTLSVerification(t *testing.T) {
+	var auth string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion": "cluster.karmada.io/v1alpha1", "kind": "ClusterList", "items": []}`))
+	}))
+	defer ts.Close()
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	list := func(caCert string, insecure bool) error {
+		client, err := dynamic.NewForConfig(restConfig(ts.URL, "token", caCert, insecure))
+		if err != nil {
+			t.Fatalf("dynamic.NewForConfig returned error: %v", err)
+		}
+		_, err = client.Resource(ClusterGVR).List(context.Background(), metav1.ListOptions{})
+		return err
+	}
+
+	if err := list(caPEM, false); err != nil {
+		t.Fatalf("expected verified connection with CA bundle, got %v", err)
+	}
+	assertEqual(t, "Authorization", auth, "Bearer token")
+	if err := list("", false); err == nil {
+		t.Fatalf("expected verification failure without CA bundle")
+	}
+	if err := list("", true); err != nil {
+		t.Fatalf("expected insecure opt-in to skip verification, got %v", err)
+	}
+}
+
+func clusterUnstructured(name, endpoint, syncMode string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cluster.karmada.io/v1alpha1",
+		"kind":       "Cluster",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       map[string]interface{}{"apiEndpoint": endpoint, "syncMode": syncMode},
+		"status": map[string]interface{}{
+			"kubernetesVersion": "v1.31.2",
+			"conditions":        []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		},
+	}}
+}
+
+type staticLister struct {
+	clusters []MemberCluster
+	calls    int
+}
+
+func (s *staticLister) GetMemberClusters(ctx context.Context) ([]MemberCluster, error) {
+	s.calls++
+	return s.clusters, nil
+}
+
+func TestClusterCache_TracksMembershipChanges(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ClusterGVR: "ClusterList"},
+		clusterUnstructured("member-b", "https://b.example", SyncModePush),
+		clusterUnstructured("member-a", "https://a.example", SyncModePull),
+	)
+	fallback := &staticLister{}
+	c := newClusterCache(client, fallback)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.Start(ctx)
+	if !cache.WaitForCacheSync(ctx.Done(), c.informer.HasSynced) {
+		t.Fatalf("cache did not sync")
+	}
+
+	clusters, err := c.GetMemberClusters(ctx)
+	if err != nil {
+		t.Fatalf("GetMemberClusters returned error: %v", err)
+	}
+	assertEqual(t, "len(clusters)", len(clusters), 2)
+	assertEqual(t, "clusters[0].Name", clusters[0].Name, "member-a")
+	assertEqual(t, "clusters[0].SyncMode", clusters[0].SyncMode, SyncModePull)
+	assertEqual(t, "clusters[1].Endpoint", clusters[1].Endpoint, "https://b.example")
+	ready, _ := clusters[1].ReadyCondition()
+	assertEqual(t, "clusters[1] Ready.Status", ready.Status, "True")
+	assertEqual(t, "fallback calls", fallback.calls, 0)
+
+	drainChanges := func() {
+		for {
+			select {
+			case <-c.Changes():
+			case <-time.After(100 * time.Millisecond):
+				return
+			}
+		}
+	}
+	drainChanges()
+
+	if _, err := client.Resource(ClusterGVR).Create(ctx, clusterUnstructured("member-c", "https://c.example", SyncModePush), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create cluster: %v", err)
+	}
+	select {
+	case <-c.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected membership change signal")
+	}
+	clusters, _ = c.GetMemberClusters(ctx)
+	assertEqual(t, "len(clusters) after add", len(clusters), 3)
+
+	// 상태만 바뀐 경우는 멤버 구성 변경으로 보지 않는다.
+	updated := clusterUnstructured("member-c", "https://c.example", SyncModePush)
+	updated.Object["status"] = map[string]interface{}{"kubernetesVersion": "v1.32.0"}
+	if _, err := client.Resource(ClusterGVR).Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update cluster: %v", err)
+	}
+	select {
+	case <-c.Changes():
+		t.Fatalf("status-only update should not signal a membership change")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestClusterCache_FallsBackBeforeSync(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ClusterGVR: "ClusterList"})
+	fallback := &staticLister{clusters: []MemberCluster{{Name: "direct"}}}
+	c := newClusterCache(client, fallback)
+
+	// Start 전에는 캐시가 동기화되지 않으므로 기다리지 않고 직접 조회한다.
+	start := time.Now()
+	clusters, err := c.GetMemberClusters(context.Background())
+	if err != nil {
+		t.Fatalf("GetMemberClusters returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected an unsynced cache to fall back without waiting, took %s", elapsed)
+	}
+	assertEqual(t, "fallback calls", fallback.calls, 1)
+	assertEqual(t, "clusters[0].Name", clusters[0].Name, "direct")
+}
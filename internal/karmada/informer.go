@@ -0,0 +1,161 @@
+package karmada
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"federation-metric-api/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var ClusterGVR = schema.GroupVersionResource{Group: "cluster.karmada.io", Version: "v1alpha1", Resource: "clusters"}
+
+// 전체 재동기화 주기
+var cacheResync = 10 * time.Minute
+
+type memberLister interface {
+	GetMemberClusters(ctx context.Context) ([]MemberCluster, error)
+}
+
+type bindingLister interface {
+	GetResourceBindings(ctx context.Context) ([]ResourceBinding, error)
+}
+
+// ClusterCache 는 Karmada Cluster 리소스를 list/watch 로 추적하는 로컬 캐시이다.
+// 첫 동기화 전에는 fallback 으로 직접 조회하고, 이후에는 Karmada 가 일시적으로 응답하지 않아도
+// 마지막으로 알려진 멤버 목록을 반환한다.
+type ClusterCache struct {
+	informer cache.SharedIndexInformer
+	fallback memberLister
+
+	startOnce sync.Once
+	changes   chan struct{}
+}
+
+// NewClusterCache 는 환경 설정의 Karmada API 주소와 토큰으로 Cluster 캐시를 만든다.
+func NewClusterCache() (*ClusterCache, error) {
+	client, err := dynamic.NewForConfig(restConfig(config.Env.KarmadaApi, config.Env.KarmadaToken,
+		config.Env.KarmadaCaCert, config.Env.KarmadaInsecure))
+	if err != nil {
+		return nil, err
+	}
+	return newClusterCache(client, NewClient()), nil
+}
+
+func newClusterCache(client dynamic.Interface, fallback memberLister) *ClusterCache {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, cacheResync)
+	c := &ClusterCache{
+		informer: factory.ForResource(ClusterGVR).Informer(),
+		fallback: fallback,
+		changes:  make(chan struct{}, 1),
+	}
+	_ = c.informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+		log.Printf("Karmada Cluster 감시 오류, 마지막으로 알려진 멤버 목록을 사용합니다: %v", err)
+	})
+	_, _ = c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.notify() },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// 상태(status)는 주기적으로 갱신되므로 멤버 구성(spec)이 바뀐 경우만 알린다.
+			if specChanged(oldObj, newObj) {
+				c.notify()
+			}
+		},
+		DeleteFunc: func(obj interface{}) { c.notify() },
+	})
+	return c
+}
+
+// Start 는 ctx 가 끝날 때까지 list/watch 를 실행한다. 여러 번 호출해도 한 번만 시작한다.
+func (c *ClusterCache) Start(ctx context.Context) {
+	c.startOnce.Do(func() {
+		go c.informer.Run(ctx.Done())
+	})
+}
+
+// Changes 는 멤버가 추가, 삭제되거나 spec 이 바뀌면 신호를 받는 채널이다.
+func (c *ClusterCache) Changes() <-chan struct{} {
+	return c.changes
+}
+
+func (c *ClusterCache) notify() {
+	select {
+	case c.changes <- struct{}{}:
+	default:
+	}
+}
+
+// GetMemberClusters 는 캐시된 멤버 목록을 이름 순으로 반환한다. 캐시가 아직 동기화되지 않았으면
+// 동기화를 기다리지 않고 바로 직접 조회한다.
+func (c *ClusterCache) GetMemberClusters(ctx context.Context) ([]MemberCluster, error) {
+	if !c.informer.HasSynced() {
+		if c.fallback == nil {
+			return nil, fmt.Errorf("karmada cluster 캐시가 동기화되지 않았습니다")
+		}
+		return c.fallback.GetMemberClusters(ctx)
+	}
+
+	clusters := make([]MemberCluster, 0)
+	for _, obj := range c.informer.GetStore().List() {
+		member, err := memberFromObject(obj)
+		if err != nil {
+			log.Printf("Karmada Cluster 변환 실패: %v", err)
+			continue
+		}
+		clusters = append(clusters, member)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+	return clusters, nil
+}
+
+// GetResourceBindings 는 캐시하지 않고 fallback 클라이언트로 바인딩을 직접 조회한다.
+func (c *ClusterCache) GetResourceBindings(ctx context.Context) ([]ResourceBinding, error) {
+	lister, ok := c.fallback.(bindingLister)
+	if !ok {
+		return nil, fmt.Errorf("karmada ResourceBinding 조회를 지원하지 않는 클라이언트입니다")
+	}
+	return lister.GetResourceBindings(ctx)
+}
+
+func decodeObject(obj interface{}) (clusterObject, error) {
+	var o clusterObject
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return o, fmt.Errorf("unexpected object type %T", obj)
+	}
+	raw, err := json.Marshal(u.Object)
+	if err != nil {
+		return o, err
+	}
+	err = json.Unmarshal(raw, &o)
+	return o, err
+}
+
+func memberFromObject(obj interface{}) (MemberCluster, error) {
+	o, err := decodeObject(obj)
+	if err != nil {
+		return MemberCluster{}, err
+	}
+	return o.member(), nil
+}
+
+func specChanged(oldObj, newObj interface{}) bool {
+	oldCluster, err := decodeObject(oldObj)
+	if err != nil {
+		return true
+	}
+	newCluster, err := decodeObject(newObj)
+	if err != nil {
+		return true
+	}
+	return !reflect.DeepEqual(oldCluster.Spec, newCluster.Spec)
+}
@@ -3,127 +3,280 @@ package metricscollector
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"federation-metric-api/internal/util"
 	"federation-metric-api/model"
+	"fmt"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/kubernetes"
-	"strconv"
+	"sort"
 )
 
 var (
-	getNodeListRaw = func(client kubernetes.Interface) ([]byte, error) {
-		return client.NodeV1().RESTClient().Get().AbsPath("api/v1/nodes").DoRaw(context.TODO())
+	getNodeListRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
+		return client.NodeV1().RESTClient().Get().AbsPath("api/v1/nodes").DoRaw(ctx)
 	}
-	getNodeMetricsRaw = func(client kubernetes.Interface) ([]byte, error) {
-		return client.NodeV1().RESTClient().Get().AbsPath("apis/metrics.k8s.io/v1beta1/nodes").DoRaw(context.TODO())
+	getNodeMetricsRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
+		return client.NodeV1().RESTClient().Get().AbsPath("apis/metrics.k8s.io/v1beta1/nodes").DoRaw(ctx)
 	}
-	listPodsOnNode = func(client kubernetes.Interface, nodeName string) (*corev1.PodList, error) {
-		return client.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{
-			FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
-		})
+	listAllPods = func(ctx context.Context, client kubernetes.Interface) ([]corev1.Pod, error) {
+		var pods []corev1.Pod
+		opts := metav1.ListOptions{Limit: podListPageSize}
+		for {
+			podList, err := client.CoreV1().Pods("").List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			pods = append(pods, podList.Items...)
+			if podList.Continue == "" {
+				return pods, nil
+			}
+			opts.Continue = podList.Continue
+		}
 	}
-	getHealthzRaw = func(client kubernetes.Interface) ([]byte, error) {
-		return client.Discovery().RESTClient().Get().AbsPath("/healthz").DoRaw(context.TODO())
+	getHealthzRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
+		return client.Discovery().RESTClient().Get().AbsPath("/healthz").DoRaw(ctx)
 	}
 )
 
-func CollectRequestMetric(clientset kubernetes.Interface) (float64, float64, error) {
+// podListPageSize 는 전체 파드 목록을 나눠 받을 때 한 번에 받는 파드 수이다.
+const podListPageSize = 500
+
+// GetNodeList 는 클러스터의 노드 목록을 조회한다. 수집 주기마다 클러스터당 한 번
+// 조회한 결과를 CollectMetricFromNodes, ListPods, CollectRequestMetricFromNodes, CollectNodeStatusFromNodes,
+// CountReady 가 공유한다. ctx 가 끝나면 조회를 중단한다.
+func GetNodeList(ctx context.Context, clientset kubernetes.Interface) (model.NodeModel, error) {
 	var node model.NodeModel
-	nodeData, err := getNodeListRaw(clientset)
+	nodeData, err := getNodeListRaw(ctx, clientset)
 	if err != nil {
-		return -1, -1, err
+		return node, err
+	}
+	if err := json.Unmarshal(nodeData, &node); err != nil {
+		return node, err
+	}
+	return node, nil
+}
+
+// ListPods 는 클러스터 전체 파드를 한 번에 조회해 spec.nodeName 으로 묶고, 노드 목록의 노드에 배치된
+// 파드만 노드 순서대로 반환한다. 노드가 없으면 조회하지 않는다. 수집 주기마다 클러스터당 한 번 조회한 결과를
+// CollectRequestMetricFromNodes, CollectNamespaceUsageFromPods 가 공유한다.
+func ListPods(ctx context.Context, clientset kubernetes.Interface, node model.NodeModel) ([]corev1.Pod, error) {
+	if len(node.Items) == 0 {
+		return nil, nil
+	}
+	all, err := listAllPods(ctx, clientset)
+	if err != nil {
+		return nil, err
 	}
-	err = json.Unmarshal(nodeData, &node)
+	byNode := make(map[string][]corev1.Pod, len(node.Items))
+	for _, pod := range all {
+		byNode[pod.Spec.NodeName] = append(byNode[pod.Spec.NodeName], pod)
+	}
+	var pods []corev1.Pod
+	for _, i := range node.Items {
+		pods = append(pods, byNode[i.MetaData.NodeName]...)
+	}
+	return pods, nil
+}
 
-	// 필터링된 Pod 목록 가져오기
+func CollectRequestMetric(ctx context.Context, clientset kubernetes.Interface) (float64, float64, error) {
+	node, err := GetNodeList(ctx, clientset)
+	if err != nil {
+		return -1, -1, err
+	}
+	pods, err := ListPods(ctx, clientset, node)
+	if err != nil {
+		return -1, -1, err
+	}
+	return CollectRequestMetricFromNodes(node, pods)
+}
 
+// CollectRequestMetricFromNodes 는 Running 파드의 requests 합계를 노드 할당 가능량(allocatable) 합계로
+// 나눈 CPU, 메모리 요청률(%)을 반환한다. pods 는 ListPods 로 조회한 노드 목록의 파드이다.
+func CollectRequestMetricFromNodes(node model.NodeModel, pods []corev1.Pod) (float64, float64, error) {
 	totalAllocatableCPU := resource.NewQuantity(0, resource.DecimalSI)
 	totalAllocatableMem := resource.NewQuantity(0, resource.BinarySI)
 	totalRequestCPU := resource.NewQuantity(0, resource.DecimalSI)
 	totalRequestMem := resource.NewQuantity(0, resource.BinarySI)
-	for _, i := range node.Items {
-		podList, _ := listPodsOnNode(clientset, i.MetaData.NodeName)
-		totalNodeRequestCPU := resource.NewQuantity(0, resource.DecimalSI)
-		totalNodeRequestMem := resource.NewQuantity(0, resource.BinarySI)
-		for _, pod := range podList.Items {
-			if pod.Status.Phase == "Running" {
-				for _, c := range pod.Spec.Containers {
-					if cpuQty, ok := c.Resources.Requests["cpu"]; ok {
-						totalNodeRequestCPU.Add(cpuQty)
-					}
-					if memQty, ok := c.Resources.Requests["memory"]; ok {
-						totalNodeRequestMem.Add(memQty)
-					}
+	for _, pod := range pods {
+		if pod.Status.Phase == "Running" {
+			for _, c := range pod.Spec.Containers {
+				if cpuQty, ok := c.Resources.Requests["cpu"]; ok {
+					totalRequestCPU.Add(cpuQty)
+				}
+				if memQty, ok := c.Resources.Requests["memory"]; ok {
+					totalRequestMem.Add(memQty)
 				}
 			}
 		}
-
-		totalRequestCPU.Add(*totalNodeRequestCPU)
-		totalRequestMem.Add(*totalNodeRequestMem)
-
-		allocatableCPU, _ := resource.ParseQuantity(i.Status.Allocatable.Cpu)
+	}
+	for _, i := range node.Items {
+		allocatableCPU, allocatableMem, err := parseQuantities(i.Status.Allocatable.Cpu, i.Status.Allocatable.Memory)
+		if err != nil {
+			return -1, -1, fmt.Errorf("node %s allocatable: %w", i.MetaData.NodeName, err)
+		}
 		totalAllocatableCPU.Add(allocatableCPU)
-		allocatableMem, _ := resource.ParseQuantity(i.Status.Allocatable.Memory)
 		totalAllocatableMem.Add(allocatableMem)
 	}
 	return (float64(totalRequestCPU.MilliValue()) / float64(totalAllocatableCPU.MilliValue())) * 100, (float64(totalRequestMem.Value()) / float64(totalAllocatableMem.Value())) * 100, nil
 
 }
 
-func CollectMetric(clientset kubernetes.Interface) (float64, float64, error) {
-	var CpuN float64 = 1000000000
-
+// GetNodeMetrics 는 metrics.k8s.io 노드 사용량을 조회한다. 수집 주기마다 클러스터당 한 번 조회한
+// 결과를 CollectMetricFromNodes, CollectNodeStatusFromNodes 가 공유한다.
+func GetNodeMetrics(ctx context.Context, clientset kubernetes.Interface) (model.NodeMetricModel, error) {
 	var nodeMetric model.NodeMetricModel
-	nodeMetricData, err := getNodeMetricsRaw(clientset)
+	nodeMetricData, err := getNodeMetricsRaw(ctx, clientset)
 	if err != nil {
-		return -1, -1, err
+		return nodeMetric, err
 	}
-	err = json.Unmarshal(nodeMetricData, &nodeMetric)
+	if err := json.Unmarshal(nodeMetricData, &nodeMetric); err != nil {
+		return nodeMetric, err
+	}
+	return nodeMetric, nil
+}
 
-	var node model.NodeModel
-	nodeData, err := getNodeListRaw(clientset)
+func CollectMetric(ctx context.Context, clientset kubernetes.Interface) (float64, float64, error) {
+	node, err := GetNodeList(ctx, clientset)
 	if err != nil {
 		return -1, -1, err
 	}
-	err = json.Unmarshal(nodeData, &node)
-
-	var ClCpuRatio, ClMemRatio, ClCpuRaw, ClMemRaw, ClCpuCore, ClMemSize float64
+	nodeMetric, err := GetNodeMetrics(ctx, clientset)
+	if err != nil {
+		return -1, -1, err
+	}
+	return CollectMetricFromNodes(node, nodeMetric)
+}
 
-	for i := range nodeMetric.Items {
+// CollectMetricFromNodes 는 metrics.k8s.io 노드 사용량 합계를 노드 용량(capacity) 합계로 나눈
+// CPU, 메모리 사용률(%)을 반환한다. 사용량이나 용량 값을 해석할 수 없으면 사용률을 왜곡하지 않도록
+// 오류를 반환한다.
+func CollectMetricFromNodes(node model.NodeModel, nodeMetric model.NodeMetricModel) (float64, float64, error) {
+	nodes := make(map[string]model.Items, len(node.Items))
+	for _, item := range node.Items {
+		nodes[item.MetaData.NodeName] = item
+	}
 
-		for j := range node.Items {
+	totalUsageCPU := resource.NewQuantity(0, resource.DecimalSI)
+	totalUsageMem := resource.NewQuantity(0, resource.BinarySI)
+	totalCapacityCPU := resource.NewQuantity(0, resource.DecimalSI)
+	totalCapacityMem := resource.NewQuantity(0, resource.BinarySI)
+	var errs []error
+	for _, item := range nodeMetric.Items {
+		n, ok := nodes[item.NodeInfo.Name]
+		if !ok {
+			continue
+		}
+		usageCPU, usageMem, err := parseQuantities(item.Usage.Cpu, item.Usage.Memory)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("node %s usage: %w", item.NodeInfo.Name, err))
+			continue
+		}
+		capacityCPU, capacityMem, err := parseQuantities(n.Status.Capacity.Cpu, n.Status.Capacity.Memory)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("node %s capacity: %w", item.NodeInfo.Name, err))
+			continue
+		}
+		totalUsageCPU.Add(usageCPU)
+		totalUsageMem.Add(usageMem)
+		totalCapacityCPU.Add(capacityCPU)
+		totalCapacityMem.Add(capacityMem)
+	}
+	if len(errs) > 0 {
+		return -1, -1, errors.Join(errs...)
+	}
 
-			if nodeMetric.Items[i].NodeInfo.Name == node.Items[j].MetaData.NodeName {
-				cpu := nodeMetric.Items[i].Usage.Cpu
-				cpu = cpu[:len(cpu)-1]
-				cpuRaw, _ := strconv.ParseFloat(cpu, 64)
-				ClCpuRaw += cpuRaw
+	return (float64(totalUsageCPU.MilliValue()) / float64(totalCapacityCPU.MilliValue())) * 100, (float64(totalUsageMem.Value()) / float64(totalCapacityMem.Value())) * 100, nil
+}
 
-				memory := nodeMetric.Items[i].Usage.Memory
-				memory = memory[:len(memory)-2]
-				memRaw, _ := strconv.ParseFloat(memory, 64)
-				ClMemRaw += memRaw
+// CollectNodeStatusFromNodes 는 노드별 Ready 상태, kubelet 버전, 아키텍처, 용량, 할당 가능량과
+// metrics.k8s.io 실사용량을 노드 이름 순으로 반환한다. nodeMetric 에 없는 노드는 사용량이 비어 있고,
+// 값 해석에 실패해도 나머지 노드 정보는 오류와 함께 반환한다.
+func CollectNodeStatusFromNodes(node model.NodeModel, nodeMetric model.NodeMetricModel) ([]model.NodeStatus, error) {
+	var errs []error
+	nodes := make([]model.NodeStatus, 0, len(node.Items))
+	for _, item := range node.Items {
+		capacity, err := nodeResources(item.Status.Capacity.Cpu, item.Status.Capacity.Memory)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("node %s capacity: %w", item.MetaData.NodeName, err))
+		}
+		allocatable, err := nodeResources(item.Status.Allocatable.Cpu, item.Status.Allocatable.Memory)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("node %s allocatable: %w", item.MetaData.NodeName, err))
+		}
+		nodes = append(nodes, model.NodeStatus{
+			NodeName:       item.MetaData.NodeName,
+			Ready:          readyCondition(item),
+			KubeletVersion: item.Status.NodeInfo.KubeletVersion,
+			Architecture:   item.Status.NodeInfo.Architecture,
+			Capacity:       capacity,
+			Allocatable:    allocatable,
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeName < nodes[j].NodeName })
 
-				cpuCore, _ := strconv.ParseFloat(node.Items[j].Status.Capacity.Cpu, 64)
-				memory = node.Items[j].Status.Capacity.Memory
-				memory = memory[:len(memory)-2]
-				memSize, _ := strconv.ParseFloat(memory, 64)
-				ClCpuCore += cpuCore
-				ClMemSize += memSize
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n.NodeName] = i
+	}
+	for _, item := range nodeMetric.Items {
+		i, ok := index[item.NodeInfo.Name]
+		if !ok {
+			continue
+		}
+		usage, err := nodeResources(item.Usage.Cpu, item.Usage.Memory)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("node %s usage: %w", item.NodeInfo.Name, err))
+			continue
+		}
+		n := &nodes[i]
+		n.Usage = &usage
+		if n.Capacity.Cpu > 0 && n.Capacity.Memory > 0 {
+			n.UsageRatio = &model.NodeUsageFloat{
+				Cpu:    util.Round(usage.Cpu/n.Capacity.Cpu*100, 2),
+				Memory: util.Round(float64(usage.Memory)/float64(n.Capacity.Memory)*100, 2),
 			}
 		}
+	}
+	return nodes, errors.Join(errs...)
+}
 
+// nodeResources 는 Kubernetes 수량 문자열을 CPU 코어 수와 메모리 바이트로 변환한다.
+func nodeResources(cpu, memory string) (model.NodeResources, error) {
+	cpuQty, memQty, err := parseQuantities(cpu, memory)
+	if err != nil {
+		return model.NodeResources{}, err
 	}
-	ClCpuRatio = (ClCpuRaw / (ClCpuCore * CpuN)) * 100
-	ClMemRatio = (ClMemRaw / ClMemSize) * 100
+	return model.NodeResources{Cpu: float64(cpuQty.MilliValue()) / 1000, Memory: memQty.Value()}, nil
+}
 
-	return ClCpuRatio, ClMemRatio, nil
+// parseQuantities 는 CPU, 메모리 수량 문자열(예: "3500m", "250000000n", "16Gi", "1024Ki")을 해석한다.
+func parseQuantities(cpu, memory string) (resource.Quantity, resource.Quantity, error) {
+	cpuQty, err := resource.ParseQuantity(cpu)
+	if err != nil {
+		return resource.Quantity{}, resource.Quantity{}, fmt.Errorf("cpu %q: %w", cpu, err)
+	}
+	memQty, err := resource.ParseQuantity(memory)
+	if err != nil {
+		return resource.Quantity{}, resource.Quantity{}, fmt.Errorf("memory %q: %w", memory, err)
+	}
+	return cpuQty, memQty, nil
+}
+
+// readyCondition 은 노드 Ready 조건의 상태(True, False, Unknown)이다.
+func readyCondition(item model.Items) string {
+	for _, condition := range item.Status.Conditions {
+		if condition.Type == "Ready" {
+			return condition.Status
+		}
+	}
+	return "Unknown"
 }
 
-func NodeHealthCheck(clientset kubernetes.Interface) string {
-	content, err := getHealthzRaw(clientset)
+func NodeHealthCheck(ctx context.Context, clientset kubernetes.Interface) string {
+	content, err := getHealthzRaw(ctx, clientset)
 	if err != nil {
 		return "Unknown"
 	}
@@ -149,14 +302,10 @@ func CountReady(node model.NodeModel) (int, int) {
 	return total, ready
 }
 
-func NodeSummary(clientset kubernetes.Interface) (int, int) {
-	var node model.NodeModel
-	data, err := getNodeListRaw(clientset)
+func NodeSummary(ctx context.Context, clientset kubernetes.Interface) (int, int) {
+	node, err := GetNodeList(ctx, clientset)
 	if err != nil {
 		return -1, -1
 	}
-	if err := json.Unmarshal(data, &node); err != nil {
-		return -1, -1
-	}
 	return CountReady(node)
 }
@@ -1,6 +1,7 @@
 package metricscollector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -8,7 +9,11 @@ import (
 	"federation-metric-api/model"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestCountReady(t *testing.T) {
@@ -75,11 +80,11 @@ func TestNodeSummary_UsesGetNodeListRaw(t *testing.T) {
 		t.Fatalf("failed to marshal node: %v", err)
 	}
 
-	getNodeListRaw = func(client kubernetes.Interface) ([]byte, error) {
+	getNodeListRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
 		return data, nil
 	}
 
-	total, ready := NodeSummary(nil)
+	total, ready := NodeSummary(context.Background(), nil)
 	if total != 2 || ready != 1 {
 		t.Fatalf("unexpected summary: total=%d ready=%d", total, ready)
 	}
@@ -89,24 +94,24 @@ func TestNodeHealthCheck_HealthyAndUnhealthy(t *testing.T) {
 	oldHealth := getHealthzRaw
 	defer func() { getHealthzRaw = oldHealth }()
 
-	getHealthzRaw = func(client kubernetes.Interface) ([]byte, error) {
+	getHealthzRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
 		return []byte("ok"), nil
 	}
-	if got := NodeHealthCheck(nil); got != "True" {
+	if got := NodeHealthCheck(context.Background(), nil); got != "True" {
 		t.Fatalf("expected True, got %q", got)
 	}
 
-	getHealthzRaw = func(client kubernetes.Interface) ([]byte, error) {
+	getHealthzRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
 		return []byte("ng"), nil
 	}
-	if got := NodeHealthCheck(nil); got != "False" {
+	if got := NodeHealthCheck(context.Background(), nil); got != "False" {
 		t.Fatalf("expected False, got %q", got)
 	}
 
-	getHealthzRaw = func(client kubernetes.Interface) ([]byte, error) {
+	getHealthzRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
 		return nil, fmt.Errorf("boom")
 	}
-	if got := NodeHealthCheck(nil); got != "Unknown" {
+	if got := NodeHealthCheck(context.Background(), nil); got != "Unknown" {
 		t.Fatalf("expected Unknown, got %q", got)
 	}
 }
@@ -153,28 +158,78 @@ func TestCollectMetric_UsesHooks(t *testing.T) {
 		t.Fatalf("marshal node: %v", err)
 	}
 
-	getNodeMetricsRaw = func(client kubernetes.Interface) ([]byte, error) {
+	getNodeMetricsRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
 		return nmBytes, nil
 	}
-	getNodeListRaw = func(client kubernetes.Interface) ([]byte, error) {
+	getNodeListRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
 		return nodeBytes, nil
 	}
 
-	cpuRatio, memRatio, err := CollectMetric(nil)
+	cpuRatio, memRatio, err := CollectMetric(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("CollectMetric returned error: %v", err)
 	}
-	if cpuRatio <= 0 || memRatio <= 0 {
-		t.Fatalf("expected positive ratios, got cpu=%f mem=%f", cpuRatio, memRatio)
+	if cpuRatio != 50 || memRatio != 50 {
+		t.Fatalf("expected cpu=50 mem=50, got cpu=%f mem=%f", cpuRatio, memRatio)
+	}
+
+	getNodeMetricsRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
+		return nil, fmt.Errorf("metrics unavailable")
+	}
+	if _, err := GetNodeMetrics(context.Background(), nil); err == nil {
+		t.Fatal("expected GetNodeMetrics to return the metrics API error")
+	}
+}
+
+func TestCollectMetricFromNodes_ParsesQuantities(t *testing.T) {
+	tests := []struct {
+		name               string
+		usageCPU, usageMem string
+		capCPU, capMem     string
+		wantCPU, wantMem   float64
+		wantErr            bool
+	}{
+		{name: "nano cores and Ki", usageCPU: "250000000n", usageMem: "1048576Ki", capCPU: "1", capMem: "4Gi", wantCPU: 25, wantMem: 25},
+		{name: "milli cpu capacity", usageCPU: "700m", usageMem: "512Mi", capCPU: "3500m", capMem: "2Gi", wantCPU: 20, wantMem: 25},
+		{name: "plain bytes", usageCPU: "1", usageMem: "1073741824", capCPU: "4", capMem: "8589934592", wantCPU: 25, wantMem: 12.5},
+		{name: "unparseable usage", usageCPU: "abc", usageMem: "1Gi", capCPU: "4", capMem: "8Gi", wantErr: true},
+		{name: "unparseable capacity", usageCPU: "1", usageMem: "1Gi", capCPU: "4", capMem: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeMetric := model.NodeMetricModel{Items: []model.NodeItem{
+				{NodeInfo: model.MetricMetadata{Name: "node1"}, Usage: model.NodeUsageString{Cpu: tt.usageCPU, Memory: tt.usageMem}},
+				// 노드 목록에 없는 노드의 지표는 무시한다.
+				{NodeInfo: model.MetricMetadata{Name: "gone"}, Usage: model.NodeUsageString{Cpu: "bogus", Memory: "bogus"}},
+			}}
+
+			node := model.NodeModel{Items: []model.Items{{
+				MetaData: model.NodeMetadata{NodeName: "node1"},
+				Status:   model.Status{Capacity: model.Capacity{Cpu: tt.capCPU, Memory: tt.capMem}},
+			}}}
+			cpu, mem, err := CollectMetricFromNodes(node, nodeMetric)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got cpu=%f mem=%f", cpu, mem)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cpu != tt.wantCPU || mem != tt.wantMem {
+				t.Fatalf("expected cpu=%v mem=%v, got cpu=%v mem=%v", tt.wantCPU, tt.wantMem, cpu, mem)
+			}
+		})
 	}
 }
 
 func TestCollectRequestMetric_UsesHooks(t *testing.T) {
 	oldGetNodes := getNodeListRaw
-	oldListPods := listPodsOnNode
+	oldListPods := listAllPods
 	defer func() {
 		getNodeListRaw = oldGetNodes
-		listPodsOnNode = oldListPods
+		listAllPods = oldListPods
 	}()
 
 	node := model.NodeModel{
@@ -195,7 +250,7 @@ func TestCollectRequestMetric_UsesHooks(t *testing.T) {
 		t.Fatalf("marshal node: %v", err)
 	}
 
-	getNodeListRaw = func(client kubernetes.Interface) ([]byte, error) {
+	getNodeListRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
 		return nodeBytes, nil
 	}
 
@@ -206,6 +261,7 @@ func TestCollectRequestMetric_UsesHooks(t *testing.T) {
 			{
 				Status: corev1.PodStatus{Phase: corev1.PodRunning},
 				Spec: corev1.PodSpec{
+					NodeName: "node1",
 					Containers: []corev1.Container{
 						{
 							Resources: corev1.ResourceRequirements{
@@ -221,18 +277,121 @@ func TestCollectRequestMetric_UsesHooks(t *testing.T) {
 		},
 	}
 
-	listPodsOnNode = func(client kubernetes.Interface, nodeName string) (*corev1.PodList, error) {
-		if nodeName != "node1" {
-			t.Fatalf("unexpected nodeName: %q", nodeName)
-		}
-		return podList, nil
+	// 노드 목록에 없는 노드와 아직 배치되지 않은 파드는 요청률에 넣지 않는다.
+	for _, nodeName := range []string{"other-node", ""} {
+		pod := podList.Items[0].DeepCopy()
+		pod.Spec.NodeName = nodeName
+		podList.Items = append(podList.Items, *pod)
 	}
 
-	cpuRatio, memRatio, err := CollectRequestMetric(nil)
+	listed := 0
+	listAllPods = func(ctx context.Context, client kubernetes.Interface) ([]corev1.Pod, error) {
+		listed++
+		return podList.Items, nil
+	}
+
+	cpuRatio, memRatio, err := CollectRequestMetric(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("CollectRequestMetric returned error: %v", err)
 	}
-	if cpuRatio <= 0 || memRatio <= 0 {
-		t.Fatalf("expected positive ratios, got cpu=%f mem=%f", cpuRatio, memRatio)
+	if cpuRatio != 25 || memRatio != 25 {
+		t.Fatalf("expected 25%% ratios, got cpu=%f mem=%f", cpuRatio, memRatio)
+	}
+	if listed != 1 {
+		t.Fatalf("expected pods listed once for the cluster, got %d lists", listed)
+	}
+
+	node.Items[0].Status.Allocatable.Cpu = "two"
+	nodeBytes, _ = json.Marshal(node)
+	if _, _, err := CollectRequestMetric(context.Background(), nil); err == nil {
+		t.Fatal("expected an error for unparseable allocatable cpu")
+	}
+}
+
+func TestListPods_PagesThroughClusterWidePods(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "node-b"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "kube-system"}, Spec: corev1.PodSpec{NodeName: "node-a"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"}},
+	)
+	lists := 0
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists++
+		if sel := action.(k8stesting.ListAction).GetListRestrictions().Fields; !sel.Empty() {
+			t.Fatalf("expected a cluster-wide list without a field selector, got %q", sel)
+		}
+		return false, nil, nil
+	})
+
+	node := model.NodeModel{Items: []model.Items{
+		{MetaData: model.NodeMetadata{NodeName: "node-a"}},
+		{MetaData: model.NodeMetadata{NodeName: "node-b"}},
+	}}
+	pods, err := ListPods(context.Background(), client, node)
+	if err != nil {
+		t.Fatalf("ListPods returned error: %v", err)
+	}
+	if lists != 1 || len(pods) != 2 || pods[0].Name != "b" || pods[1].Name != "a" {
+		t.Fatalf("expected one list grouped in node order, got %d lists and %+v", lists, pods)
+	}
+}
+
+func TestCollectNodeStatusFromNodes(t *testing.T) {
+	node := model.NodeModel{
+		Items: []model.Items{
+			{
+				MetaData: model.NodeMetadata{NodeName: "node-b"},
+				Status: model.Status{
+					Capacity:    model.Capacity{Cpu: "4", Memory: "8Gi"},
+					Allocatable: model.Allocatable{Cpu: "3500m", Memory: "7Gi"},
+					NodeInfo:    model.NodeInfo{KubeletVersion: "v1.30.1", Architecture: "arm64"},
+					Conditions:  []model.Conditions{{Type: "Ready", Status: "False"}},
+				},
+			},
+			{
+				MetaData: model.NodeMetadata{NodeName: "node-a"},
+				Status: model.Status{
+					Capacity:    model.Capacity{Cpu: "2", Memory: "4Gi"},
+					Allocatable: model.Allocatable{Cpu: "2", Memory: "4Gi"},
+					NodeInfo:    model.NodeInfo{KubeletVersion: "v1.30.1", Architecture: "amd64"},
+					Conditions:  []model.Conditions{{Type: "Ready", Status: "True"}},
+				},
+			},
+		},
+	}
+	nodeMetric := model.NodeMetricModel{
+		Items: []model.NodeItem{
+			{NodeInfo: model.MetricMetadata{Name: "node-a"}, Usage: model.NodeUsageString{Cpu: "500000000n", Memory: "1048576Ki"}},
+			{NodeInfo: model.MetricMetadata{Name: "node-b"}, Usage: model.NodeUsageString{Cpu: "bogus", Memory: "1Gi"}},
+		},
+	}
+	nodes, err := CollectNodeStatusFromNodes(node, nodeMetric)
+	if err == nil {
+		t.Fatal("expected an error for the unparseable usage of node-b")
+	}
+	if len(nodes) != 2 || nodes[0].NodeName != "node-a" || nodes[1].NodeName != "node-b" {
+		t.Fatalf("expected nodes sorted by name, got %+v", nodes)
+	}
+
+	a := nodes[0]
+	if a.Ready != "True" || a.Architecture != "amd64" || a.KubeletVersion != "v1.30.1" {
+		t.Fatalf("unexpected node info: %+v", a)
+	}
+	if a.Usage == nil || a.Usage.Cpu != 0.5 || a.Usage.Memory != 1<<30 {
+		t.Fatalf("unexpected usage: %+v", a.Usage)
+	}
+	if a.UsageRatio == nil || a.UsageRatio.Cpu != 25 || a.UsageRatio.Memory != 25 {
+		t.Fatalf("unexpected usage ratio: %+v", a.UsageRatio)
+	}
+
+	b := nodes[1]
+	if b.Ready != "False" || b.Allocatable.Cpu != 3.5 || b.Capacity.Memory != 8<<30 || b.Usage != nil {
+		t.Fatalf("unexpected node-b: %+v", b)
+	}
+
+	// 노드 사용량을 조회하지 못하면 빈 지표가 전달된다.
+	nodes, err = CollectNodeStatusFromNodes(node, model.NodeMetricModel{})
+	if err != nil || len(nodes) != 2 || nodes[0].Usage != nil {
+		t.Fatalf("expected node info without usage when metrics are unavailable, got %+v (%v)", nodes, err)
 	}
 }
@@ -0,0 +1,226 @@
+package metricscollector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"federation-metric-api/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+var getPodMetricsRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
+	return client.NodeV1().RESTClient().Get().AbsPath("apis/metrics.k8s.io/v1beta1/pods").DoRaw(ctx)
+}
+
+// usageTotals 는 파드 자원 합계를 resource.Quantity 로 누적한다.
+type usageTotals struct {
+	pods                   int
+	usageCPU, usageMem     resource.Quantity
+	requestCPU, requestMem resource.Quantity
+	limitCPU, limitMem     resource.Quantity
+	workloads              map[workloadKey]*usageTotals
+	hasUsage               bool
+}
+
+type workloadKey struct {
+	kind, name string
+}
+
+// CollectNamespaceUsageFromPods 는 ListPods 로 조회한 Running 파드를 네임스페이스와 소유 워크로드
+// (Deployment, StatefulSet, DaemonSet, Job)별로 묶어 metrics.k8s.io 실사용량과 requests/limits 합계를
+// 반환한다. 결과는 CPU 사용량이 큰 순서이다. 파드 지표 조회나 값 해석에 실패해도
+// requests/limits 합계는 오류와 함께 반환한다.
+func CollectNamespaceUsageFromPods(ctx context.Context, clientset kubernetes.Interface, pods []corev1.Pod) ([]model.NamespaceUsage, error) {
+	namespaces := map[string]*usageTotals{}
+	owners := map[string]workloadKey{}
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		ns := totalsFor(namespaces, pod.Namespace)
+		key := podWorkload(pod)
+		owners[pod.Namespace+"/"+pod.Name] = key
+		for _, t := range []*usageTotals{ns, ns.workload(key)} {
+			t.pods++
+			for _, c := range pod.Spec.Containers {
+				addQuantity(&t.requestCPU, c.Resources.Requests, corev1.ResourceCPU)
+				addQuantity(&t.requestMem, c.Resources.Requests, corev1.ResourceMemory)
+				addQuantity(&t.limitCPU, c.Resources.Limits, corev1.ResourceCPU)
+				addQuantity(&t.limitMem, c.Resources.Limits, corev1.ResourceMemory)
+			}
+		}
+	}
+
+	err := addPodUsage(ctx, clientset, namespaces, owners)
+	return rankNamespaces(namespaces), err
+}
+
+// addPodUsage 는 파드 지표를 조회해 Running 파드로 집계된 네임스페이스, 워크로드에 실사용량을 더한다.
+func addPodUsage(ctx context.Context, clientset kubernetes.Interface, namespaces map[string]*usageTotals, owners map[string]workloadKey) error {
+	data, err := getPodMetricsRaw(ctx, clientset)
+	if err != nil {
+		return err
+	}
+	var podMetric model.PodMetricModel
+	if err := json.Unmarshal(data, &podMetric); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, item := range podMetric.Items {
+		key, ok := owners[item.Metadata.Namespace+"/"+item.Metadata.Name]
+		if !ok {
+			continue
+		}
+		var cpu, mem resource.Quantity
+		valid := true
+		for _, c := range item.Containers {
+			cpuQty, err := resource.ParseQuantity(c.Usage.Cpu)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("pod %s/%s cpu %q: %w", item.Metadata.Namespace, item.Metadata.Name, c.Usage.Cpu, err))
+				valid = false
+				break
+			}
+			memQty, err := resource.ParseQuantity(c.Usage.Memory)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("pod %s/%s memory %q: %w", item.Metadata.Namespace, item.Metadata.Name, c.Usage.Memory, err))
+				valid = false
+				break
+			}
+			cpu.Add(cpuQty)
+			mem.Add(memQty)
+		}
+		if !valid {
+			continue
+		}
+		ns := namespaces[item.Metadata.Namespace]
+		for _, t := range []*usageTotals{ns, ns.workload(key)} {
+			t.usageCPU.Add(cpu)
+			t.usageMem.Add(mem)
+			t.hasUsage = true
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func totalsFor(m map[string]*usageTotals, name string) *usageTotals {
+	t, ok := m[name]
+	if !ok {
+		t = &usageTotals{workloads: map[workloadKey]*usageTotals{}}
+		m[name] = t
+	}
+	return t
+}
+
+func (t *usageTotals) workload(key workloadKey) *usageTotals {
+	w, ok := t.workloads[key]
+	if !ok {
+		w = &usageTotals{}
+		t.workloads[key] = w
+	}
+	return w
+}
+
+func addQuantity(total *resource.Quantity, list corev1.ResourceList, name corev1.ResourceName) {
+	if qty, ok := list[name]; ok {
+		total.Add(qty)
+	}
+}
+
+// podWorkload 는 파드를 소유한 워크로드이다. ReplicaSet 은 pod-template-hash 를 떼어 Deployment 로,
+// 컨트롤러가 없는 파드는 Pod 자신으로 본다.
+func podWorkload(pod corev1.Pod) workloadKey {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		if ref.Kind == "ReplicaSet" {
+			if hash := pod.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+				return workloadKey{kind: "Deployment", name: strings.TrimSuffix(ref.Name, "-"+hash)}
+			}
+		}
+		return workloadKey{kind: ref.Kind, name: ref.Name}
+	}
+	return workloadKey{kind: "Pod", name: pod.Name}
+}
+
+func (t *usageTotals) resources() (usage *model.NodeResources, requests, limits model.NodeResources) {
+	if t.hasUsage {
+		usage = &model.NodeResources{Cpu: float64(t.usageCPU.MilliValue()) / 1000, Memory: t.usageMem.Value()}
+	}
+	requests = model.NodeResources{Cpu: float64(t.requestCPU.MilliValue()) / 1000, Memory: t.requestMem.Value()}
+	limits = model.NodeResources{Cpu: float64(t.limitCPU.MilliValue()) / 1000, Memory: t.limitMem.Value()}
+	return
+}
+
+func rankNamespaces(namespaces map[string]*usageTotals) []model.NamespaceUsage {
+	result := make([]model.NamespaceUsage, 0, len(namespaces))
+	for name, t := range namespaces {
+		usage, requests, limits := t.resources()
+		ns := model.NamespaceUsage{Namespace: name, Pods: t.pods, Usage: usage, Requests: requests, Limits: limits}
+		for key, w := range t.workloads {
+			usage, requests, limits := w.resources()
+			ns.Workloads = append(ns.Workloads, model.WorkloadUsage{
+				Kind: key.kind, Name: key.name, Pods: w.pods, Usage: usage, Requests: requests, Limits: limits,
+			})
+		}
+		sort.Slice(ns.Workloads, func(i, j int) bool {
+			a, b := ns.Workloads[i], ns.Workloads[j]
+			return Heavier(a.Usage, a.Requests, a.Kind+"/"+a.Name, b.Usage, b.Requests, b.Kind+"/"+b.Name)
+		})
+		result = append(result, ns)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		return Heavier(a.Usage, a.Requests, a.Namespace, b.Usage, b.Requests, b.Namespace)
+	})
+	return result
+}
+
+// Heavier 는 실사용 CPU, 실사용 메모리, 요청 CPU, 요청 메모리, 이름 순으로 a 가 앞서는지 비교한다.
+func Heavier(aUsage *model.NodeResources, aReq model.NodeResources, aName string,
+	bUsage *model.NodeResources, bReq model.NodeResources, bName string) bool {
+	var au, bu model.NodeResources
+	if aUsage != nil {
+		au = *aUsage
+	}
+	if bUsage != nil {
+		bu = *bUsage
+	}
+	switch {
+	case au.Cpu != bu.Cpu:
+		return au.Cpu > bu.Cpu
+	case au.Memory != bu.Memory:
+		return au.Memory > bu.Memory
+	case aReq.Cpu != bReq.Cpu:
+		return aReq.Cpu > bReq.Cpu
+	case aReq.Memory != bReq.Memory:
+		return aReq.Memory > bReq.Memory
+	}
+	return aName < bName
+}
+
+// TopNamespaces 는 사용량 순으로 정렬된 namespaces 에서 상위 n 개 네임스페이스와 각 네임스페이스의
+// 상위 n 개 워크로드만 남긴다. n 이 0 이하이면 자르지 않는다.
+func TopNamespaces(namespaces []model.NamespaceUsage, n int) []model.NamespaceUsage {
+	if n <= 0 {
+		return namespaces
+	}
+	if len(namespaces) > n {
+		namespaces = namespaces[:n]
+	}
+	top := make([]model.NamespaceUsage, len(namespaces))
+	for i, ns := range namespaces {
+		if len(ns.Workloads) > n {
+			ns.Workloads = ns.Workloads[:n]
+		}
+		top[i] = ns
+	}
+	return top
+}
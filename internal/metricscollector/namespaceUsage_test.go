@@ -0,0 +1,114 @@
+package metricscollector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"federation-metric-api/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func testPod(namespace, name, ownerKind, ownerName, hash string, phase corev1.PodPhase, cpu, mem string) corev1.Pod {
+	controller := true
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{}},
+		Status:     corev1.PodStatus{Phase: phase},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem)},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+			},
+		}}},
+	}
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &controller}}
+	}
+	if hash != "" {
+		pod.Labels["pod-template-hash"] = hash
+	}
+	return pod
+}
+
+func TestCollectNamespaceUsageFromPods(t *testing.T) {
+	oldPodMetrics := getPodMetricsRaw
+	defer func() { getPodMetricsRaw = oldPodMetrics }()
+
+	pods := []corev1.Pod{
+		testPod("shop", "web-7d9f-a", "ReplicaSet", "web-7d9f", "7d9f", corev1.PodRunning, "250m", "256Mi"),
+		testPod("shop", "db-0", "StatefulSet", "db", "", corev1.PodRunning, "1", "1Gi"),
+		testPod("shop", "done", "Job", "migrate", "", corev1.PodSucceeded, "4", "4Gi"),
+		testPod("shop", "web-7d9f-b", "ReplicaSet", "web-7d9f", "7d9f", corev1.PodRunning, "250m", "256Mi"),
+		testPod("tools", "debug", "", "", "", corev1.PodRunning, "100m", "64Mi"),
+	}
+
+	podMetric := model.PodMetricModel{Items: []model.PodMetricItem{
+		{Metadata: model.PodMetricMetadata{Namespace: "shop", Name: "web-7d9f-a"}, Containers: []model.ContainerMetricItem{{Usage: model.NodeUsageString{Cpu: "100000000n", Memory: "100Mi"}}}},
+		{Metadata: model.PodMetricMetadata{Namespace: "shop", Name: "web-7d9f-b"}, Containers: []model.ContainerMetricItem{{Usage: model.NodeUsageString{Cpu: "300m", Memory: "100Mi"}}}},
+		{Metadata: model.PodMetricMetadata{Namespace: "shop", Name: "db-0"}, Containers: []model.ContainerMetricItem{{Usage: model.NodeUsageString{Cpu: "200m", Memory: "512Mi"}}}},
+		{Metadata: model.PodMetricMetadata{Namespace: "tools", Name: "debug"}, Containers: []model.ContainerMetricItem{{Usage: model.NodeUsageString{Cpu: "??", Memory: "1Mi"}}}},
+	}}
+	data, _ := json.Marshal(podMetric)
+	getPodMetricsRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) { return data, nil }
+
+	namespaces, err := CollectNamespaceUsageFromPods(context.Background(), nil, pods)
+	if err == nil {
+		t.Fatal("expected an error for the unparseable pod usage")
+	}
+	if len(namespaces) != 2 || namespaces[0].Namespace != "shop" || namespaces[1].Namespace != "tools" {
+		t.Fatalf("expected namespaces ranked by usage, got %+v", namespaces)
+	}
+
+	shop := namespaces[0]
+	if shop.Pods != 3 || shop.Usage == nil || shop.Usage.Cpu != 0.6 || shop.Usage.Memory != 712<<20 {
+		t.Fatalf("unexpected shop usage: %+v %+v", shop, shop.Usage)
+	}
+	if shop.Requests.Cpu != 1.5 || shop.Requests.Memory != 1536<<20 || shop.Limits.Cpu != 1.5 || shop.Limits.Memory != 0 {
+		t.Fatalf("unexpected shop requests/limits: %+v %+v", shop.Requests, shop.Limits)
+	}
+	if len(shop.Workloads) != 2 {
+		t.Fatalf("expected the ReplicaSet pods to be grouped by Deployment, got %+v", shop.Workloads)
+	}
+	web := shop.Workloads[0]
+	if web.Kind != "Deployment" || web.Name != "web" || web.Pods != 2 || web.Usage.Cpu != 0.4 {
+		t.Fatalf("unexpected top workload: %+v", web)
+	}
+	if db := shop.Workloads[1]; db.Kind != "StatefulSet" || db.Name != "db" || db.Usage.Cpu != 0.2 {
+		t.Fatalf("unexpected second workload: %+v", db)
+	}
+
+	tools := namespaces[1]
+	if tools.Usage != nil || tools.Requests.Cpu != 0.1 || tools.Workloads[0].Kind != "Pod" {
+		t.Fatalf("expected tools without usage but with requests, got %+v", tools)
+	}
+
+	getPodMetricsRaw = func(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
+		return nil, fmt.Errorf("metrics unavailable")
+	}
+	namespaces, err = CollectNamespaceUsageFromPods(context.Background(), nil, pods)
+	if err == nil || len(namespaces) != 2 || namespaces[0].Usage != nil {
+		t.Fatalf("expected requests without usage when pod metrics are unavailable, got %+v (%v)", namespaces, err)
+	}
+}
+
+func TestTopNamespaces(t *testing.T) {
+	namespaces := []model.NamespaceUsage{
+		{Namespace: "a", Workloads: []model.WorkloadUsage{{Name: "w1"}, {Name: "w2"}, {Name: "w3"}}},
+		{Namespace: "b"},
+		{Namespace: "c"},
+	}
+	top := TopNamespaces(namespaces, 2)
+	if len(top) != 2 || top[0].Namespace != "a" || len(top[0].Workloads) != 2 {
+		t.Fatalf("unexpected top namespaces: %+v", top)
+	}
+	if len(namespaces[0].Workloads) != 3 {
+		t.Fatal("TopNamespaces must not modify its input")
+	}
+	if got := TopNamespaces(namespaces, 0); len(got) != 3 {
+		t.Fatalf("expected no cut-off for n=0, got %d", len(got))
+	}
+}
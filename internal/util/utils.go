@@ -1,8 +1,29 @@
 package util
 
-import "math"
+import (
+	"math"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
 
 func Round(num float64, decimals int) float64 {
 	pow10 := math.Pow10(decimals)
 	return math.Round(num*pow10) / pow10
 }
+
+// KubeconfigServer 는 kubeconfig 의 현재 컨텍스트가 가리키는 API 서버 주소를 반환한다.
+func KubeconfigServer(kubeconfig string) string {
+	cfg, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return ""
+	}
+	ctx, ok := cfg.Contexts[cfg.CurrentContext]
+	if !ok {
+		return ""
+	}
+	cluster, ok := cfg.Clusters[ctx.Cluster]
+	if !ok {
+		return ""
+	}
+	return cluster.Server
+}
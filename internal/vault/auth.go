@@ -0,0 +1,117 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Vault 인증 방식
+const (
+	AuthMethodAppRole    = "approle"
+	AuthMethodKubernetes = "kubernetes"
+	AuthMethodJWT        = "jwt"
+	AuthMethodToken      = "token"
+)
+
+const defaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+func (cfg *Config) authMethod() string {
+	if cfg.AuthMethod == "" {
+		return AuthMethodAppRole
+	}
+	return strings.ToLower(cfg.AuthMethod)
+}
+
+func (cfg *Config) loginPath() string {
+	mount := cfg.AuthMount
+	if mount == "" {
+		mount = cfg.authMethod()
+	}
+	return fmt.Sprintf("auth/%s/login", strings.Trim(mount, "/"))
+}
+
+// readFileTrimmed 는 토큰 파일을 읽는다. projected service account 토큰이나 Vault Agent
+// sink 처럼 주기적으로 교체되는 파일이므로 로그인할 때마다 다시 읽는다.
+func readFileTrimmed(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
+
+// authenticate 는 설정된 인증 방식으로 로그인하고 토큰 정보를 담은 Secret 을 반환한다.
+func authenticate(client *api.Client, cfg *Config) (*api.Secret, error) {
+	switch cfg.authMethod() {
+	case AuthMethodAppRole:
+		return client.Logical().Write(cfg.loginPath(), map[string]interface{}{
+			"role_id":   cfg.RoleID,
+			"secret_id": cfg.SecretID,
+		})
+	case AuthMethodKubernetes, AuthMethodJWT:
+		jwtPath := cfg.JWTPath
+		if jwtPath == "" && cfg.authMethod() == AuthMethodKubernetes {
+			jwtPath = defaultServiceAccountTokenPath
+		}
+		if jwtPath == "" {
+			return nil, errors.New("vault jwt auth requires VaultJwtPath")
+		}
+		jwt, err := readFileTrimmed(jwtPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vault jwt: %w", err)
+		}
+		return client.Logical().Write(cfg.loginPath(), map[string]interface{}{
+			"role": cfg.AuthRole,
+			"jwt":  jwt,
+		})
+	case AuthMethodToken:
+		return tokenFileAuth(client, cfg)
+	default:
+		return nil, fmt.Errorf("unsupported vault auth method %q", cfg.AuthMethod)
+	}
+}
+
+// tokenFileAuth 는 파일에 저장된 토큰을 사용한다. 토큰의 TTL 과 갱신 가능 여부는 lookup-self 로 확인한다.
+func tokenFileAuth(client *api.Client, cfg *Config) (*api.Secret, error) {
+	if cfg.TokenPath == "" {
+		return nil, errors.New("vault token auth requires VaultTokenPath")
+	}
+	token, err := readFileTrimmed(cfg.TokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault token: %w", err)
+	}
+
+	lookupClient, err := client.Clone()
+	if err != nil {
+		return nil, err
+	}
+	lookupClient.SetToken(token)
+	self, err := lookupClient.Auth().Token().LookupSelf()
+	if err != nil {
+		return nil, err
+	}
+	ttl, _ := self.TokenTTL()
+	renewable, _ := self.TokenIsRenewable()
+
+	return &api.Secret{
+		Auth: &api.SecretAuth{
+			ClientToken:   token,
+			LeaseDuration: int(ttl.Seconds()),
+			Renewable:     renewable,
+		},
+	}, nil
+}
+
+// revokeOnClose 는 종료 시 토큰을 폐기할지 결정한다. 파일로 전달받은 토큰은
+// 이 프로세스가 발급한 것이 아니므로 폐기하지 않는다.
+func (cfg *Config) revokeOnClose() bool {
+	return cfg == nil || cfg.authMethod() != AuthMethodToken
+}
@@ -2,9 +2,15 @@ package vault
 
 import (
 	"federation-metric-api/config"
+	"federation-metric-api/internal/util"
 	"federation-metric-api/model"
 	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/vault/api"
 )
@@ -13,71 +19,200 @@ type Config struct {
 	URL      string
 	RoleID   string
 	SecretID string
+	// CACert 는 Vault 서버 인증서 검증에 사용할 PEM 형식 CA 번들이다.
+	CACert string
+
+	// AuthMethod 는 approle(기본값), kubernetes, jwt, token 중 하나이다.
+	AuthMethod string
+	// AuthMount 는 인증 방식의 마운트 경로이다. 비어 있으면 인증 방식 이름을 사용한다.
+	AuthMount string
+	// AuthRole 은 kubernetes, jwt 인증에 사용할 Vault 역할이다.
+	AuthRole string
+	// JWTPath 는 kubernetes, jwt 인증에 사용할 토큰 파일 경로이다.
+	JWTPath string
+	// TokenPath 는 token 인증에 사용할 Vault 토큰 파일 경로이다.
+	TokenPath string
+
+	// Layout 은 클러스터 시크릿이 저장된 KV 경로와 필드 이름이다.
+	Layout Layout
 }
 
 func ConfigFromEnv() *Config {
+	fields, err := ParseFieldMap(config.Env.VaultKvFields)
+	if err != nil {
+		log.Printf("%v, 기본 필드 이름을 사용합니다", err)
+		fields = FieldMap{}
+	}
 	return &Config{
-		URL:      config.Env.VaultUrl,
-		RoleID:   config.Env.VaultRoleId,
-		SecretID: config.Env.VaultSecretId,
+		URL:        config.Env.VaultUrl,
+		RoleID:     config.Env.VaultRoleId,
+		SecretID:   config.Env.VaultSecretId,
+		CACert:     config.Env.VaultCaCert,
+		AuthMethod: config.Env.VaultAuthMethod,
+		AuthMount:  config.Env.VaultAuthMount,
+		AuthRole:   config.Env.VaultAuthRole,
+		JWTPath:    config.Env.VaultJwtPath,
+		TokenPath:  config.Env.VaultTokenPath,
+		Layout: Layout{
+			Mount:   config.Env.VaultKvMount,
+			Prefix:  config.Env.VaultKvPrefix,
+			Version: config.Env.VaultKvVersion,
+			Fields:  fields,
+		},
 	}
 }
 
 type Client struct {
-	api *api.Client
+	api    *api.Client
+	cfg    *Config
+	layout Layout
+
+	// loginMu 는 동시에 여러 번 재로그인하지 않도록 보호한다.
+	loginMu sync.Mutex
+	auth    *api.Secret
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// clusters 는 WatchClusters 로 감시 중인 클러스터 시크릿 상태이다.
+	watchMu  sync.Mutex
+	clusters *clusterWatch
+
+	// folders 는 하위 key 가 있는 폴더로 확인된 "/" 로 끝나는 key 이다. 다음 조회부터는 시크릿으로
+	// 읽어 보지 않고 바로 하위를 조회한다.
+	folderMu sync.Mutex
+	folders  map[string]bool
 }
 
+// NewClient 는 설정된 인증 방식으로 로그인한 Vault 클라이언트를 만들고 토큰 갱신을 시작한다.
+// 클라이언트는 프로세스 수명 동안 재사용하고 종료 시 Close 로 토큰을 폐기해야 한다.
 func NewClient(cfg *Config) (*Client, error) {
 	vaultCfg := api.DefaultConfig()
 	vaultCfg.Address = cfg.URL
+	if cfg.CACert != "" {
+		if err := vaultCfg.ConfigureTLS(&api.TLSConfig{CACertBytes: []byte(cfg.CACert)}); err != nil {
+			return nil, err
+		}
+	}
 
 	client, err := api.NewClient(vaultCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := client.Logical().Write("auth/approle/login", map[string]interface{}{
-		"role_id":   cfg.RoleID,
-		"secret_id": cfg.SecretID,
-	})
-	if err != nil {
+	c := &Client{
+		api:    client,
+		cfg:    cfg,
+		layout: cfg.Layout,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := c.login(); err != nil {
 		return nil, err
 	}
-	client.SetToken(resp.Auth.ClientToken)
+	go c.manageToken()
 
-	return &Client{api: client}, nil
+	return c, nil
 }
 
-func extractClusterInfos(keys []interface{}, readSecret func(path string) (*api.Secret, error)) []model.ClusterCredential {
+// extractClusterInfos 는 keys 의 시크릿을 읽어 유효한 클러스터 인증 정보만 반환한다.
+// ClusterID 는 key 의 마지막 경로 요소이다.
+func (l Layout) extractClusterInfos(keys []interface{}, readSecret func(path string) (*api.Secret, error)) []model.ClusterCredential {
+	l = l.normalized()
+
 	var infos []model.ClusterCredential
 	for _, key := range keys {
-		path := fmt.Sprintf("secret/data/cluster/%s", key.(string))
-		secret, err := readSecret(path)
-		if err != nil || secret == nil || secret.Data == nil || secret.Data["data"] == nil {
-			continue
-		}
-		data, ok := secret.Data["data"].(map[string]interface{})
-		if !ok {
+		secret, err := readSecret(l.readPath(key.(string)))
+		if err != nil {
 			continue
 		}
-		apiURL, ok := data["clusterApiUrl"].(string)
-		if !ok {
-			continue
+		if info, ok := l.credential(key.(string), secret); ok {
+			infos = append(infos, info)
 		}
-		token, ok := data["clusterToken"].(string)
-		if !ok {
+	}
+	return infos
+}
+
+// credential 은 key 에서 읽은 secret 을 클러스터 인증 정보로 변환한다. 필수 필드가 없으면 false 를 반환한다.
+func (l Layout) credential(key string, secret *api.Secret) (model.ClusterCredential, bool) {
+	l = l.normalized()
+	f := l.Fields
+
+	data, ok := l.secretData(secret)
+	if !ok {
+		return model.ClusterCredential{}, false
+	}
+	token, _ := data[f.Token].(string)
+	clientCert, _ := data[f.ClientCert].(string)
+	clientKey, _ := data[f.ClientKey].(string)
+	kubeconfig, _ := data[f.Kubeconfig].(string)
+	caCert, _ := data[f.CACert].(string)
+
+	apiURL, _ := data[f.APIURL].(string)
+	if apiURL == "" && kubeconfig != "" {
+		apiURL = util.KubeconfigServer(kubeconfig)
+	}
+	if apiURL == "" {
+		return model.ClusterCredential{}, false
+	}
+	if token == "" && (clientCert == "" || clientKey == "") && kubeconfig == "" {
+		return model.ClusterCredential{}, false
+	}
+
+	return model.ClusterCredential{
+		ClusterID:    path.Base(strings.TrimSuffix(key, "/")),
+		APIServerURL: apiURL,
+		BearerToken:  token,
+		ClientCert:   clientCert,
+		ClientKey:    clientKey,
+		Kubeconfig:   kubeconfig,
+		CACert:       caCert,
+		Insecure:     parseBool(data[f.Insecure]),
+	}, true
+}
+
+// duplicateWarned 는 ClusterID 중복 경고를 경로 조합당 한 번만 남기기 위한 기록이다.
+var duplicateWarned sync.Map
+
+// uniqueClusters 는 key 별 인증 정보를 ClusterID 순으로 반환한다. 다른 폴더의 시크릿이 같은 ClusterID 를
+// 가지면(예: prod/member1, dev/member1) 서로 덮어쓰지 않도록 경로 순으로 첫 번째만 사용하고 경고를 남긴다.
+func uniqueClusters(byKey map[string]model.ClusterCredential) []model.ClusterCredential {
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	owner := make(map[string]string, len(keys))
+	infos := make([]model.ClusterCredential, 0, len(keys))
+	for _, key := range keys {
+		info := byKey[key]
+		if first, dup := owner[info.ClusterID]; dup {
+			if _, warned := duplicateWarned.LoadOrStore(first+"|"+key, true); !warned {
+				log.Printf("Vault 시크릿 %s 와 %s 의 클러스터 ID %s 가 중복되어 %s 를 사용하지 않습니다", first, key, info.ClusterID, key)
+			}
 			continue
 		}
-
-		infos = append(infos, model.ClusterCredential{
-			ClusterID:    strings.TrimSuffix(key.(string), "/"),
-			APIServerURL: apiURL,
-			BearerToken:  token,
-		})
+		owner[info.ClusterID] = key
+		infos = append(infos, info)
 	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].ClusterID < infos[j].ClusterID })
 	return infos
 }
 
+// parseBool 은 Vault 시크릿 값이 bool 또는 "true" 문자열인 경우를 모두 처리한다.
+func parseBool(v interface{}) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		parsed, _ := strconv.ParseBool(b)
+		return parsed
+	}
+	return false
+}
+
 var (
 	logicalList = func(c *api.Client, path string) (*api.Secret, error) {
 		return c.Logical().List(path)
@@ -87,19 +222,108 @@ var (
 	}
 )
 
-func (c *Client) GetClusterInfos() ([]model.ClusterCredential, error) {
-	secrets, err := logicalList(c.api, "secret/metadata/cluster")
+// maxFolderDepth 는 중첩 폴더를 재귀 조회할 최대 깊이이다.
+const maxFolderDepth = 8
+
+func (c *Client) listKeys(folder string) ([]interface{}, error) {
+	secrets, err := logicalList(c.api, c.layout.normalized().listPath(folder))
+	if isPermissionDenied(err) && c.cfg != nil {
+		// 토큰이 만료되었거나 폐기된 경우 재로그인 후 한 번 더 시도한다.
+		if err := c.login(); err != nil {
+			return nil, err
+		}
+		secrets, err = logicalList(c.api, c.layout.normalized().listPath(folder))
+	}
 	if err != nil {
 		return nil, err
 	}
-
+	if secrets == nil || secrets.Data == nil {
+		return nil, nil
+	}
 	keys, ok := secrets.Data["keys"].([]interface{})
 	if !ok {
 		return nil, fmt.Errorf("unexpected type for keys")
 	}
+	return keys, nil
+}
 
-	infos := extractClusterInfos(keys, func(path string) (*api.Secret, error) {
+// isFolder 는 key 가 폴더로 확인되었는지 반환한다.
+func (c *Client) isFolder(key string) bool {
+	c.folderMu.Lock()
+	defer c.folderMu.Unlock()
+	return c.folders[key]
+}
+
+// markFolder 는 하위 key 가 조회된 key 를 폴더로 기록한다.
+func (c *Client) markFolder(key string) {
+	c.folderMu.Lock()
+	defer c.folderMu.Unlock()
+	if c.folders == nil {
+		c.folders = map[string]bool{}
+	}
+	c.folders[key] = true
+}
+
+// collect 는 folder 하위 keys 의 클러스터 시크릿을 읽어 found 에 key 별로 채운다. "/" 로 끝나는 key 는
+// 처음에는 시크릿으로 읽어 보고, 유효한 클러스터 정보가 없으면 하위 폴더로 보고 재귀 조회한다.
+// 하위 key 가 있어 폴더로 확인된 key 는 다음 조회부터 읽지 않는다.
+func (c *Client) collect(folder string, keys []interface{}, depth int, found map[string]model.ClusterCredential) error {
+	read := func(path string) (*api.Secret, error) {
 		return logicalRead(c.api, path)
-	})
-	return infos, nil
+	}
+
+	for _, key := range keys {
+		name, ok := key.(string)
+		if !ok {
+			continue
+		}
+		if !c.isFolder(folder + name) {
+			infos := c.layout.extractClusterInfos([]interface{}{folder + name}, read)
+			if len(infos) > 0 {
+				found[folder+name] = infos[0]
+				continue
+			}
+			if !strings.HasSuffix(name, "/") {
+				continue
+			}
+		}
+		if depth >= maxFolderDepth {
+			log.Printf("Vault 폴더 깊이 초과로 %s%s 하위를 건너뜁니다", folder, name)
+			continue
+		}
+		nestedKeys, err := c.listKeys(folder + name)
+		if err != nil {
+			return err
+		}
+		if len(nestedKeys) > 0 {
+			c.markFolder(folder + name)
+		}
+		if err := c.collect(folder+name, nestedKeys, depth+1, found); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetClusterInfos 는 클러스터 인증 정보를 반환한다. 감시 중이면 감시 결과를 반환하며, 감시 결과가
+// 오래되었으면 오류를 반환해 호출자가 의존성 실패로 처리하도록 한다.
+func (c *Client) GetClusterInfos() ([]model.ClusterCredential, error) {
+	if infos, ok, err := c.watchedClusters(); ok {
+		if err != nil {
+			return nil, err
+		}
+		return infos, nil
+	}
+	keys, err := c.listKeys("")
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		return nil, fmt.Errorf("no cluster secrets under %s", c.layout.normalized().listPath(""))
+	}
+	found := map[string]model.ClusterCredential{}
+	if err := c.collect("", keys, 0, found); err != nil {
+		return nil, err
+	}
+	return uniqueClusters(found), nil
 }
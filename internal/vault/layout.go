@@ -0,0 +1,142 @@
+package vault
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// FieldMap 은 클러스터 시크릿에서 각 인증 정보를 읽을 필드 이름이다.
+type FieldMap struct {
+	APIURL     string
+	Token      string
+	ClientCert string
+	ClientKey  string
+	Kubeconfig string
+	CACert     string
+	Insecure   string
+}
+
+// Layout 은 클러스터 시크릿이 저장된 KV 엔진의 마운트, 경로, 버전과 필드 이름이다.
+// 비어 있는 값은 기본값(secret 마운트, cluster 경로, KV v2, cluster* 필드)을 사용한다.
+type Layout struct {
+	Mount   string
+	Prefix  string
+	Version int
+	Fields  FieldMap
+}
+
+func defaultFieldMap() FieldMap {
+	return FieldMap{
+		APIURL:     "clusterApiUrl",
+		Token:      "clusterToken",
+		ClientCert: "clusterClientCert",
+		ClientKey:  "clusterClientKey",
+		Kubeconfig: "clusterKubeconfig",
+		CACert:     "clusterCaCert",
+		Insecure:   "clusterInsecure",
+	}
+}
+
+func (l Layout) normalized() Layout {
+	if l.Mount == "" {
+		l.Mount = "secret"
+	}
+	if l.Prefix == "" {
+		l.Prefix = "cluster"
+	}
+	if l.Version == 0 {
+		l.Version = 2
+	}
+	def := defaultFieldMap()
+	for _, f := range []struct {
+		value *string
+		def   string
+	}{
+		{&l.Fields.APIURL, def.APIURL},
+		{&l.Fields.Token, def.Token},
+		{&l.Fields.ClientCert, def.ClientCert},
+		{&l.Fields.ClientKey, def.ClientKey},
+		{&l.Fields.Kubeconfig, def.Kubeconfig},
+		{&l.Fields.CACert, def.CACert},
+		{&l.Fields.Insecure, def.Insecure},
+	} {
+		if *f.value == "" {
+			*f.value = f.def
+		}
+	}
+	return l
+}
+
+// join 은 경로 구분자를 정리하되 하위 폴더를 뜻하는 끝의 "/" 는 유지한다.
+func (l Layout) join(kind, key string) string {
+	parts := []string{strings.Trim(l.Mount, "/")}
+	if l.Version == 2 {
+		parts = append(parts, kind)
+	}
+	parts = append(parts, strings.Trim(l.Prefix, "/"))
+	joined := path.Join(append(parts, key)...)
+	if strings.HasSuffix(key, "/") {
+		joined += "/"
+	}
+	return joined
+}
+
+// listPath 는 prefix 하위 key 폴더의 목록 조회 경로이다.
+func (l Layout) listPath(key string) string {
+	return strings.TrimSuffix(l.join("metadata", key), "/")
+}
+
+// readPath 는 prefix 하위 key 시크릿의 조회 경로이다.
+func (l Layout) readPath(key string) string {
+	return l.join("data", key)
+}
+
+// secretData 는 KV 버전에 맞게 시크릿 본문을 꺼낸다.
+func (l Layout) secretData(secret *api.Secret) (map[string]interface{}, bool) {
+	if secret == nil || secret.Data == nil {
+		return nil, false
+	}
+	if l.Version == 1 {
+		return secret.Data, true
+	}
+	data, ok := secret.Data["data"].(map[string]interface{})
+	return data, ok
+}
+
+// ParseFieldMap 은 "apiUrl=server,token=saToken" 형식의 필드 매핑을 해석한다.
+func ParseFieldMap(spec string) (FieldMap, error) {
+	var fields FieldMap
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fields, fmt.Errorf("invalid vault field mapping %q", pair)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(name) {
+		case "apiUrl":
+			fields.APIURL = value
+		case "token":
+			fields.Token = value
+		case "clientCert":
+			fields.ClientCert = value
+		case "clientKey":
+			fields.ClientKey = value
+		case "kubeconfig":
+			fields.Kubeconfig = value
+		case "caCert":
+			fields.CACert = value
+		case "insecure":
+			fields.Insecure = value
+		default:
+			return fields, fmt.Errorf("unknown vault field mapping key %q", name)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,135 @@
+package vault
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+var (
+	reloginInitialBackoff = 1 * time.Second
+	reloginMaxBackoff     = 1 * time.Minute
+)
+
+func isPermissionDenied(err error) bool {
+	var respErr *api.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden
+}
+
+// login 은 설정된 인증 방식으로 로그인해 새 토큰을 설정한다.
+func (c *Client) login() error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	resp, err := authenticate(c.api, c.cfg)
+	if err != nil {
+		return err
+	}
+	if resp == nil || resp.Auth == nil {
+		return errors.New("vault login returned no auth data")
+	}
+	c.api.SetToken(resp.Auth.ClientToken)
+	c.auth = resp
+	return nil
+}
+
+func (c *Client) currentAuth() *api.Secret {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	return c.auth
+}
+
+// manageToken 은 LifetimeWatcher 로 토큰을 갱신하고, 갱신할 수 없거나 최대 TTL 에 도달하면
+// 재로그인한다. Close 가 호출될 때까지 실행된다.
+func (c *Client) manageToken() {
+	defer close(c.done)
+
+	for {
+		auth := c.currentAuth()
+		if auth.Auth.LeaseDuration == 0 {
+			// 만료되지 않는 토큰은 갱신할 필요가 없다.
+			<-c.stop
+			return
+		}
+
+		watcher, err := c.api.NewLifetimeWatcher(&api.LifetimeWatcherInput{Secret: auth})
+		if err != nil {
+			log.Printf("Vault 토큰 갱신 감시 생성 실패: %v", err)
+			if !c.reloginWithBackoff() {
+				return
+			}
+			continue
+		}
+		go watcher.Start()
+
+		if !c.watch(watcher) {
+			return
+		}
+		if !c.reloginWithBackoff() {
+			return
+		}
+	}
+}
+
+// watch 는 토큰 갱신 결과를 기록하다가 감시가 끝나면 true, Close 가 호출되면 false 를 반환한다.
+func (c *Client) watch(watcher *api.LifetimeWatcher) bool {
+	defer watcher.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return false
+		case err := <-watcher.DoneCh():
+			if err != nil {
+				log.Printf("Vault 토큰 갱신 실패, 재로그인합니다: %v", err)
+			} else {
+				log.Printf("Vault 토큰 최대 TTL 도달, 재로그인합니다")
+			}
+			return true
+		case renewal := <-watcher.RenewCh():
+			if renewal.Secret != nil && renewal.Secret.Auth != nil {
+				log.Printf("Vault 토큰 갱신 완료 (ttl %ds)", renewal.Secret.Auth.LeaseDuration)
+			}
+		}
+	}
+}
+
+// reloginWithBackoff 는 성공할 때까지 지수 백오프로 재로그인한다. Close 가 호출되면 false 를 반환한다.
+func (c *Client) reloginWithBackoff() bool {
+	backoff := reloginInitialBackoff
+	for {
+		err := c.login()
+		if err == nil {
+			return true
+		}
+		log.Printf("Vault 재로그인 실패, %s 후 재시도: %v", backoff, err)
+
+		select {
+		case <-c.stop:
+			return false
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > reloginMaxBackoff {
+			backoff = reloginMaxBackoff
+		}
+	}
+}
+
+// Close 는 토큰 갱신을 멈추고 현재 토큰을 폐기한다.
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		if c.stop != nil {
+			close(c.stop)
+			<-c.done
+		}
+		if c.api != nil && c.api.Token() != "" && c.cfg.revokeOnClose() {
+			err = c.api.Auth().Token().RevokeSelf("")
+			c.api.ClearToken()
+		}
+	})
+	return err
+}
@@ -1,10 +1,21 @@
 package vault
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"federation-metric-api/config"
+	"federation-metric-api/model"
 	"github.com/hashicorp/vault/api"
 )
 
@@ -74,7 +85,7 @@ func Test_extractClusterInfos_ParsesValidSecrets(t *testing.T) {
 		},
 	}
 
-	got := extractClusterInfos(keys, func(path string) (*api.Secret, error) {
+	got := Layout{}.extractClusterInfos(keys, func(path string) (*api.Secret, error) {
 		if s, ok := secrets[path]; ok {
 			return s, nil
 		}
@@ -92,6 +103,105 @@ func Test_extractClusterInfos_ParsesValidSecrets(t *testing.T) {
 	}
 }
 
+func Test_extractClusterInfos_ParsesTLSFields(t *testing.T) {
+	keys := []interface{}{"ca/", "insecure/"}
+
+	secrets := map[string]*api.Secret{
+		"secret/data/cluster/ca/": {
+			Data: map[string]interface{}{
+				"data": map[string]interface{}{
+					"clusterApiUrl": "https://ca.api",
+					"clusterToken":  "token-ca",
+					"clusterCaCert": "-----BEGIN CERTIFICATE-----",
+				},
+			},
+		},
+		"secret/data/cluster/insecure/": {
+			Data: map[string]interface{}{
+				"data": map[string]interface{}{
+					"clusterApiUrl":   "https://insecure.api",
+					"clusterToken":    "token-insecure",
+					"clusterInsecure": "true",
+				},
+			},
+		},
+	}
+
+	got := Layout{}.extractClusterInfos(keys, func(path string) (*api.Secret, error) {
+		return secrets[path], nil
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 cluster infos, got %d", len(got))
+	}
+	if got[0].CACert != "-----BEGIN CERTIFICATE-----" || got[0].Insecure {
+		t.Fatalf("unexpected TLS fields for first cluster: %+v", got[0])
+	}
+	if got[1].CACert != "" || !got[1].Insecure {
+		t.Fatalf("unexpected TLS fields for second cluster: %+v", got[1])
+	}
+}
+
+func Test_extractClusterInfos_ParsesAuthMethods(t *testing.T) {
+	keys := []interface{}{"cert/", "kubeconfig/", "no-auth/"}
+
+	secrets := map[string]*api.Secret{
+		"secret/data/cluster/cert/": {
+			Data: map[string]interface{}{
+				"data": map[string]interface{}{
+					"clusterApiUrl":     "https://cert.api",
+					"clusterClientCert": "cert-pem",
+					"clusterClientKey":  "key-pem",
+				},
+			},
+		},
+		"secret/data/cluster/kubeconfig/": {
+			Data: map[string]interface{}{
+				"data": map[string]interface{}{
+					"clusterKubeconfig": `apiVersion: v1
+kind: Config
+clusters:
+- name: kc
+  cluster:
+    server: https://kc.api:6443
+users:
+- name: kc
+  user:
+    token: kc-token
+contexts:
+- name: kc
+  context:
+    cluster: kc
+    user: kc
+current-context: kc
+`,
+				},
+			},
+		},
+		"secret/data/cluster/no-auth/": {
+			Data: map[string]interface{}{
+				"data": map[string]interface{}{
+					"clusterApiUrl": "https://no-auth.api",
+				},
+			},
+		},
+	}
+
+	got := Layout{}.extractClusterInfos(keys, func(path string) (*api.Secret, error) {
+		return secrets[path], nil
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 cluster infos, got %d: %+v", len(got), got)
+	}
+	if got[0].ClusterID != "cert" || got[0].ClientCert != "cert-pem" || got[0].ClientKey != "key-pem" || got[0].BearerToken != "" {
+		t.Fatalf("unexpected client certificate cluster: %+v", got[0])
+	}
+	if got[1].ClusterID != "kubeconfig" || got[1].APIServerURL != "https://kc.api:6443" || got[1].Kubeconfig == "" {
+		t.Fatalf("unexpected kubeconfig cluster: %+v", got[1])
+	}
+}
+
 func Test_extractClusterInfos_SkipsInvalid(t *testing.T) {
 	keys := []interface{}{"ok/", "bad/"}
 
@@ -106,7 +216,7 @@ func Test_extractClusterInfos_SkipsInvalid(t *testing.T) {
 		},
 	}
 
-	got := extractClusterInfos(keys, func(path string) (*api.Secret, error) {
+	got := Layout{}.extractClusterInfos(keys, func(path string) (*api.Secret, error) {
 		if s, ok := secrets[path]; ok {
 			return s, nil
 		}
@@ -208,3 +318,608 @@ func TestGetClusterInfos_SuccessWithHooks(t *testing.T) {
 		t.Fatalf("unexpected cluster info: %+v", infos[0])
 	}
 }
+
+func TestGetClusterInfos_NestedFoldersWithLayout(t *testing.T) {
+	oldList := logicalList
+	oldRead := logicalRead
+	defer func() {
+		logicalList = oldList
+		logicalRead = oldRead
+	}()
+
+	listings := map[string][]interface{}{
+		"kv/federation":          {"dev/", "edge"},
+		"kv/federation/dev":      {"cluster-a", "team/"},
+		"kv/federation/dev/team": {"cluster-b"},
+	}
+	secrets := map[string]map[string]interface{}{
+		"kv/federation/edge":               {"server": "https://edge.api", "sa": "token-edge"},
+		"kv/federation/dev/cluster-a":      {"server": "https://a.api", "sa": "token-a", "skipTLS": "true"},
+		"kv/federation/dev/team/cluster-b": {"server": "https://b.api", "sa": "token-b"},
+	}
+
+	logicalList = func(c *api.Client, path string) (*api.Secret, error) {
+		keys, ok := listings[path]
+		if !ok {
+			t.Fatalf("unexpected list path: %q", path)
+		}
+		return &api.Secret{Data: map[string]interface{}{"keys": keys}}, nil
+	}
+	logicalRead = func(c *api.Client, path string) (*api.Secret, error) {
+		data, ok := secrets[path]
+		if !ok {
+			return nil, nil
+		}
+		return &api.Secret{Data: data}, nil
+	}
+
+	fields, err := ParseFieldMap("apiUrl=server, token=sa, insecure=skipTLS")
+	if err != nil {
+		t.Fatalf("ParseFieldMap returned error: %v", err)
+	}
+	c := &Client{
+		api:    &api.Client{},
+		layout: Layout{Mount: "kv", Prefix: "federation", Version: 1, Fields: fields},
+	}
+	infos, err := c.GetClusterInfos()
+	if err != nil {
+		t.Fatalf("GetClusterInfos returned error: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, info := range infos {
+		got[info.ClusterID] = info.APIServerURL + " " + info.BearerToken
+	}
+	want := map[string]string{
+		"cluster-a": "https://a.api token-a",
+		"cluster-b": "https://b.api token-b",
+		"edge":      "https://edge.api token-edge",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected clusters: %+v", infos)
+	}
+	for id, v := range want {
+		if got[id] != v {
+			t.Fatalf("cluster %s: got %q, want %q", id, got[id], v)
+		}
+	}
+	for _, info := range infos {
+		if info.ClusterID == "cluster-a" && !info.Insecure {
+			t.Fatalf("expected mapped insecure field to be honoured")
+		}
+	}
+}
+
+func TestGetClusterInfos_ReadsFolderKeysOnlyUntilConfirmed(t *testing.T) {
+	oldList := logicalList
+	oldRead := logicalRead
+	defer func() {
+		logicalList = oldList
+		logicalRead = oldRead
+	}()
+
+	listings := map[string][]interface{}{
+		"secret/metadata/cluster":      {"legacy/", "team/"},
+		"secret/metadata/cluster/team": {"cluster-b"},
+	}
+	var reads []string
+	logicalList = func(c *api.Client, path string) (*api.Secret, error) {
+		return &api.Secret{Data: map[string]interface{}{"keys": listings[path]}}, nil
+	}
+	logicalRead = func(c *api.Client, path string) (*api.Secret, error) {
+		key := strings.TrimPrefix(path, "secret/data/cluster/")
+		reads = append(reads, key)
+		if key == "team/" {
+			return nil, nil
+		}
+		return &api.Secret{Data: map[string]interface{}{
+			"data": map[string]interface{}{"clusterApiUrl": "https://" + strings.TrimSuffix(key, "/"), "clusterToken": "token"},
+		}}, nil
+	}
+
+	c := &Client{api: &api.Client{}}
+	for i := 0; i < 2; i++ {
+		reads = nil
+		infos, err := c.GetClusterInfos()
+		if err != nil {
+			t.Fatalf("GetClusterInfos returned error: %v", err)
+		}
+		if len(infos) != 2 {
+			t.Fatalf("expected the legacy and nested clusters, got %+v", infos)
+		}
+	}
+	// 폴더로 확인된 team/ 은 두 번째 조회부터 읽지 않고, 시크릿인 legacy/ 는 계속 읽는다.
+	sort.Strings(reads)
+	if strings.Join(reads, ",") != "legacy/,team/cluster-b" {
+		t.Fatalf("expected folder keys to be read only until confirmed, got %v", reads)
+	}
+}
+
+func TestGetClusterInfos_DuplicateClusterIDsKeepFirstPath(t *testing.T) {
+	oldList := logicalList
+	oldRead := logicalRead
+	defer func() {
+		logicalList = oldList
+		logicalRead = oldRead
+	}()
+
+	listings := map[string][]interface{}{
+		"secret/metadata/cluster":      {"prod/", "dev/"},
+		"secret/metadata/cluster/prod": {"member1"},
+		"secret/metadata/cluster/dev":  {"member1", "member2"},
+	}
+	logicalList = func(c *api.Client, path string) (*api.Secret, error) {
+		return &api.Secret{Data: map[string]interface{}{"keys": listings[path]}}, nil
+	}
+	logicalRead = func(c *api.Client, path string) (*api.Secret, error) {
+		key, ok := strings.CutPrefix(path, "secret/data/cluster/")
+		if !ok || strings.HasSuffix(key, "/") {
+			return nil, nil
+		}
+		return &api.Secret{Data: map[string]interface{}{
+			"data": map[string]interface{}{"clusterApiUrl": "https://" + strings.ReplaceAll(key, "/", "-"), "clusterToken": "token"},
+		}}, nil
+	}
+
+	infos, err := (&Client{api: &api.Client{}}).GetClusterInfos()
+	if err != nil {
+		t.Fatalf("GetClusterInfos returned error: %v", err)
+	}
+	if len(infos) != 2 || infos[0].ClusterID != "member1" || infos[1].ClusterID != "member2" {
+		t.Fatalf("expected one member1 and member2, got %+v", infos)
+	}
+	// 경로 순으로 dev/member1 이 prod/member1 보다 앞선다.
+	if infos[0].APIServerURL != "https://dev-member1" {
+		t.Fatalf("expected the first path to win, got %s", infos[0].APIServerURL)
+	}
+}
+
+func TestLayout_Paths(t *testing.T) {
+	v2 := Layout{}.normalized()
+	if got := v2.listPath("env/"); got != "secret/metadata/cluster/env" {
+		t.Fatalf("v2 list path: %q", got)
+	}
+	if got := v2.readPath("env/cluster-a"); got != "secret/data/cluster/env/cluster-a" {
+		t.Fatalf("v2 read path: %q", got)
+	}
+
+	v1 := Layout{Mount: "/kv/", Prefix: "clusters/prod/", Version: 1}.normalized()
+	if got := v1.listPath(""); got != "kv/clusters/prod" {
+		t.Fatalf("v1 list path: %q", got)
+	}
+	if got := v1.readPath("cluster-a"); got != "kv/clusters/prod/cluster-a" {
+		t.Fatalf("v1 read path: %q", got)
+	}
+}
+
+func TestParseFieldMap(t *testing.T) {
+	fields, err := ParseFieldMap("apiUrl=server,caCert=ca")
+	if err != nil {
+		t.Fatalf("ParseFieldMap returned error: %v", err)
+	}
+	if fields.APIURL != "server" || fields.CACert != "ca" || fields.Token != "" {
+		t.Fatalf("unexpected field map: %+v", fields)
+	}
+	if _, err := ParseFieldMap("apiUrl"); err == nil {
+		t.Fatalf("expected error for missing value")
+	}
+	if _, err := ParseFieldMap("unknown=x"); err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+}
+
+// fakeVaultServer 는 로그인, 목록 조회, 토큰 폐기만 처리하는 최소 Vault 서버이다.
+type fakeVaultServer struct {
+	mu         sync.Mutex
+	logins     int
+	loginPaths []string
+	loginData  []map[string]interface{}
+	denied     map[string]bool
+	revoked    []string
+	listedBy   []string
+}
+
+func (f *fakeVaultServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	token := r.Header.Get("X-Vault-Token")
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/auth/") && strings.HasSuffix(r.URL.Path, "/login"):
+		body := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.loginPaths = append(f.loginPaths, r.URL.Path)
+		f.loginData = append(f.loginData, body)
+		f.logins++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{
+				"client_token":   fmt.Sprintf("token-%d", f.logins),
+				"lease_duration": 3600,
+				"renewable":      true,
+			},
+		})
+	case r.URL.Path == "/v1/auth/token/lookup-self":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": token, "ttl": 600, "renewable": false},
+		})
+	case r.URL.Path == "/v1/secret/metadata/cluster":
+		f.listedBy = append(f.listedBy, token)
+		if f.denied[token] {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"keys":[]}}`))
+	case r.URL.Path == "/v1/auth/token/revoke-self":
+		f.revoked = append(f.revoked, token)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestClient_ReloginOnPermissionDeniedAndRevokeOnClose(t *testing.T) {
+	fake := &fakeVaultServer{denied: map[string]bool{"token-1": true}}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	c, err := NewClient(&Config{URL: ts.URL, RoleID: "role", SecretID: "secret"})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+
+	if _, err := c.GetClusterInfos(); err != nil {
+		t.Fatalf("GetClusterInfos returned error: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.logins != 2 {
+		t.Fatalf("expected re-login after 403, got %d logins", fake.logins)
+	}
+	if len(fake.listedBy) != 2 || fake.listedBy[1] != "token-2" {
+		t.Fatalf("expected retry with new token, got %v", fake.listedBy)
+	}
+	if len(fake.revoked) != 1 || fake.revoked[0] != "token-2" {
+		t.Fatalf("expected current token to be revoked on close, got %v", fake.revoked)
+	}
+}
+
+func TestNewClient_AuthMethods(t *testing.T) {
+	dir := t.TempDir()
+	jwtPath := filepath.Join(dir, "jwt")
+	tokenPath := filepath.Join(dir, "token")
+	if err := os.WriteFile(jwtPath, []byte("sa-jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tokenPath, []byte("static-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		wantPath  string
+		wantData  map[string]interface{}
+		wantToken string
+	}{
+		{
+			name:     "approle default",
+			cfg:      Config{RoleID: "role", SecretID: "secret"},
+			wantPath: "/v1/auth/approle/login",
+			wantData: map[string]interface{}{"role_id": "role", "secret_id": "secret"},
+		},
+		{
+			name:     "kubernetes",
+			cfg:      Config{AuthMethod: AuthMethodKubernetes, AuthRole: "collector", JWTPath: jwtPath},
+			wantPath: "/v1/auth/kubernetes/login",
+			wantData: map[string]interface{}{"role": "collector", "jwt": "sa-jwt"},
+		},
+		{
+			name:     "jwt custom mount",
+			cfg:      Config{AuthMethod: AuthMethodJWT, AuthMount: "oidc-prod", AuthRole: "collector", JWTPath: jwtPath},
+			wantPath: "/v1/auth/oidc-prod/login",
+			wantData: map[string]interface{}{"role": "collector", "jwt": "sa-jwt"},
+		},
+		{
+			name:      "token file",
+			cfg:       Config{AuthMethod: AuthMethodToken, TokenPath: tokenPath},
+			wantToken: "static-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeVaultServer{}
+			ts := httptest.NewServer(fake)
+			defer ts.Close()
+
+			cfg := tt.cfg
+			cfg.URL = ts.URL
+			c, err := NewClient(&cfg)
+			if err != nil {
+				t.Fatalf("NewClient returned error: %v", err)
+			}
+			token := c.api.Token()
+			if err := c.Close(); err != nil {
+				t.Fatalf("Close returned error: %v", err)
+			}
+
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			if tt.wantToken != "" {
+				if token != tt.wantToken {
+					t.Fatalf("token = %q, want %q", token, tt.wantToken)
+				}
+				if len(fake.loginPaths) != 0 || len(fake.revoked) != 0 {
+					t.Fatalf("token file auth must not login or revoke: logins=%v revoked=%v", fake.loginPaths, fake.revoked)
+				}
+				return
+			}
+			if len(fake.loginPaths) != 1 || fake.loginPaths[0] != tt.wantPath {
+				t.Fatalf("login paths = %v, want %q", fake.loginPaths, tt.wantPath)
+			}
+			for k, v := range tt.wantData {
+				if fake.loginData[0][k] != v {
+					t.Fatalf("login data[%q] = %v, want %v", k, fake.loginData[0][k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestNewClient_UnsupportedAuthMethod(t *testing.T) {
+	ts := httptest.NewServer(&fakeVaultServer{})
+	defer ts.Close()
+
+	if _, err := NewClient(&Config{URL: ts.URL, AuthMethod: "ldap"}); err == nil {
+		t.Fatalf("expected error for unsupported auth method")
+	}
+}
+
+func TestSyncClusters_EmitsEventsOnlyForChangedVersions(t *testing.T) {
+	oldList := logicalList
+	oldRead := logicalRead
+	defer func() {
+		logicalList = oldList
+		logicalRead = oldRead
+	}()
+
+	var mu sync.Mutex
+	keys := []interface{}{"cluster-a", "team/"}
+	versions := map[string]int{"cluster-a": 1, "team/cluster-b": 1}
+	urls := map[string]string{"cluster-a": "https://a.api", "team/cluster-b": "https://b.api"}
+	var dataReads []string
+
+	logicalList = func(c *api.Client, path string) (*api.Secret, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch path {
+		case "secret/metadata/cluster":
+			return &api.Secret{Data: map[string]interface{}{"keys": keys}}, nil
+		case "secret/metadata/cluster/team":
+			return &api.Secret{Data: map[string]interface{}{"keys": []interface{}{"cluster-b"}}}, nil
+		}
+		t.Fatalf("unexpected list path: %q", path)
+		return nil, nil
+	}
+	logicalRead = func(c *api.Client, path string) (*api.Secret, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if key, ok := strings.CutPrefix(path, "secret/metadata/cluster/"); ok {
+			v, exists := versions[key]
+			if !exists {
+				return nil, nil
+			}
+			return &api.Secret{Data: map[string]interface{}{"current_version": v, "updated_time": fmt.Sprintf("t%d", v)}}, nil
+		}
+		key := strings.TrimPrefix(path, "secret/data/cluster/")
+		dataReads = append(dataReads, key)
+		return &api.Secret{Data: map[string]interface{}{
+			"data": map[string]interface{}{"clusterApiUrl": urls[key], "clusterToken": "token"},
+		}}, nil
+	}
+
+	c := &Client{api: &api.Client{}, clusters: &clusterWatch{versions: map[string]string{}, creds: map[string]model.ClusterCredential{}}}
+	events := make(chan model.CredentialEvent, 10)
+	drain := func() []string {
+		var got []string
+		for {
+			select {
+			case ev := <-events:
+				got = append(got, ev.Type+":"+ev.ClusterID)
+			default:
+				sort.Strings(got)
+				return got
+			}
+		}
+	}
+
+	if err := c.syncClusters(events); err != nil {
+		t.Fatalf("initial sync failed: %v", err)
+	}
+	if got := drain(); strings.Join(got, ",") != "added:cluster-a,added:cluster-b" {
+		t.Fatalf("unexpected initial events: %v", got)
+	}
+
+	// 버전이 그대로이면 시크릿을 다시 읽지 않고, 폴더로 확인된 team/ 의 버전도 확인하지 않는다.
+	dataReads = nil
+	metaReads := 0
+	read := logicalRead
+	logicalRead = func(c *api.Client, path string) (*api.Secret, error) {
+		if path == "secret/metadata/cluster/team/" {
+			metaReads++
+		}
+		return read(c, path)
+	}
+	if err := c.syncClusters(events); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	logicalRead = read
+	if len(dataReads) != 0 || len(drain()) != 0 {
+		t.Fatalf("expected no reads or events for unchanged versions, reads=%v", dataReads)
+	}
+	if metaReads != 0 {
+		t.Fatalf("expected the confirmed folder not to be read, got %d metadata reads", metaReads)
+	}
+
+	mu.Lock()
+	versions["cluster-a"] = 2
+	urls["cluster-a"] = "https://a2.api"
+	keys = []interface{}{"cluster-a", "cluster-c"}
+	versions["cluster-c"] = 1
+	urls["cluster-c"] = "https://c.api"
+	mu.Unlock()
+
+	dataReads = nil
+	if err := c.syncClusters(events); err != nil {
+		t.Fatalf("third sync failed: %v", err)
+	}
+	if got := drain(); strings.Join(got, ",") != "added:cluster-c,removed:cluster-b,updated:cluster-a" {
+		t.Fatalf("unexpected change events: %v", got)
+	}
+	sort.Strings(dataReads)
+	if strings.Join(dataReads, ",") != "cluster-a,cluster-c" {
+		t.Fatalf("expected only changed secrets to be read, got %v", dataReads)
+	}
+
+	logicalList = func(c *api.Client, path string) (*api.Secret, error) {
+		t.Fatalf("GetClusterInfos should use watched state, listed %q", path)
+		return nil, nil
+	}
+	infos, err := c.GetClusterInfos()
+	if err != nil {
+		t.Fatalf("GetClusterInfos returned error: %v", err)
+	}
+	if len(infos) != 2 || infos[0].ClusterID != "cluster-a" || infos[0].APIServerURL != "https://a2.api" || infos[1].ClusterID != "cluster-c" {
+		t.Fatalf("unexpected watched clusters: %+v", infos)
+	}
+}
+
+func TestSyncClusters_RetriesSecretsThatFailedToRead(t *testing.T) {
+	oldList := logicalList
+	oldRead := logicalRead
+	defer func() {
+		logicalList = oldList
+		logicalRead = oldRead
+	}()
+
+	version := 1
+	readFails := false
+	logicalList = func(c *api.Client, path string) (*api.Secret, error) {
+		return &api.Secret{Data: map[string]interface{}{"keys": []interface{}{"a"}}}, nil
+	}
+	logicalRead = func(c *api.Client, path string) (*api.Secret, error) {
+		if path == "secret/metadata/cluster/a" {
+			return &api.Secret{Data: map[string]interface{}{"current_version": version, "updated_time": "t"}}, nil
+		}
+		if readFails {
+			return nil, errors.New("vault unavailable")
+		}
+		return &api.Secret{Data: map[string]interface{}{
+			"data": map[string]interface{}{"clusterApiUrl": fmt.Sprintf("https://a%d.api", version), "clusterToken": "token"},
+		}}, nil
+	}
+
+	c := &Client{api: &api.Client{}, clusters: &clusterWatch{versions: map[string]string{}, creds: map[string]model.ClusterCredential{}}}
+	events := make(chan model.CredentialEvent, 10)
+	if err := c.syncClusters(events); err != nil {
+		t.Fatalf("initial sync failed: %v", err)
+	}
+	<-events
+
+	// 버전이 바뀐 뒤 읽기에 실패하면 오류를 반환하고 이전 인증 정보를 유지한다.
+	version, readFails = 2, true
+	if err := c.syncClusters(events); err == nil {
+		t.Fatal("expected the read failure to be returned")
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events for a failed read, got %v", <-events)
+	}
+	if infos, _, _ := c.watchedClusters(); len(infos) != 1 || infos[0].APIServerURL != "https://a1.api" {
+		t.Fatalf("expected the previous credential to be kept, got %+v", infos)
+	}
+
+	// Vault 가 복구되면 같은 버전을 다시 읽어 반영한다.
+	readFails = false
+	if err := c.syncClusters(events); err != nil {
+		t.Fatalf("recovery sync failed: %v", err)
+	}
+	if ev := <-events; ev.Type != model.CredentialUpdated || ev.Credential.APIServerURL != "https://a2.api" {
+		t.Fatalf("unexpected event after recovery: %+v", ev)
+	}
+}
+
+func TestSyncClusters_KVv1ReadsEachSecretOncePerSync(t *testing.T) {
+	oldList := logicalList
+	oldRead := logicalRead
+	defer func() {
+		logicalList = oldList
+		logicalRead = oldRead
+	}()
+
+	url := "https://a1.api"
+	reads := 0
+	logicalList = func(c *api.Client, path string) (*api.Secret, error) {
+		return &api.Secret{Data: map[string]interface{}{"keys": []interface{}{"a"}}}, nil
+	}
+	logicalRead = func(c *api.Client, path string) (*api.Secret, error) {
+		if path != "kv/federation/a" {
+			t.Fatalf("unexpected read path: %q", path)
+		}
+		reads++
+		return &api.Secret{Data: map[string]interface{}{"clusterApiUrl": url, "clusterToken": "token"}}, nil
+	}
+
+	c := &Client{
+		api:      &api.Client{},
+		layout:   Layout{Mount: "kv", Prefix: "federation", Version: 1},
+		clusters: &clusterWatch{versions: map[string]string{}, creds: map[string]model.ClusterCredential{}},
+	}
+	events := make(chan model.CredentialEvent, 10)
+	if err := c.syncClusters(events); err != nil {
+		t.Fatalf("initial sync failed: %v", err)
+	}
+	if ev := <-events; ev.Type != model.CredentialAdded || reads != 1 {
+		t.Fatalf("expected one read for a new secret, got event %+v and %d reads", ev, reads)
+	}
+
+	// 내용이 바뀌어도 버전 확인에서 읽은 시크릿을 그대로 사용한다.
+	url, reads = "https://a2.api", 0
+	if err := c.syncClusters(events); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	if ev := <-events; ev.Type != model.CredentialUpdated || ev.Credential.APIServerURL != "https://a2.api" || reads != 1 {
+		t.Fatalf("expected one read for a changed secret, got event %+v and %d reads", ev, reads)
+	}
+}
+
+func TestGetClusterInfos_ErrorsWhenWatchIsStale(t *testing.T) {
+	w := &clusterWatch{
+		synced:   true,
+		interval: 15 * time.Second,
+		creds:    map[string]model.ClusterCredential{"a": {ClusterID: "a", APIServerURL: "https://a.api"}},
+		lastSync: time.Now().Add(-30 * time.Second),
+		lastErr:  errors.New("connection refused"),
+	}
+	c := &Client{clusters: w}
+
+	// 최근 실패는 아직 허용 범위 안이다.
+	if infos, err := c.GetClusterInfos(); err != nil || len(infos) != 1 {
+		t.Fatalf("expected watched clusters within the stale window, got %v (%v)", infos, err)
+	}
+
+	w.lastSync = time.Now().Add(-time.Minute)
+	if _, err := c.GetClusterInfos(); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("expected a stale error carrying the last sync error, got %v", err)
+	}
+
+	// 동기화가 다시 성공하면 오류가 사라진다.
+	w.lastErr = nil
+	if _, err := c.GetClusterInfos(); err != nil {
+		t.Fatalf("expected no error after a successful sync, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"federation-metric-api/model"
+	"github.com/hashicorp/vault/api"
+)
+
+// clusterWatch 는 마지막으로 확인한 시크릿별 버전과 인증 정보이다. key 는 prefix 기준 상대 경로이다.
+type clusterWatch struct {
+	mu       sync.RWMutex
+	synced   bool
+	versions map[string]string
+	creds    map[string]model.ClusterCredential
+
+	// interval 은 확인 주기이고, lastSync 와 lastErr 는 마지막으로 오류 없이 끝난 동기화 시각과 이후 오류이다.
+	interval time.Duration
+	lastSync time.Time
+	lastErr  error
+}
+
+// watchStaleIntervals 는 감시 결과를 오래된 것으로 보는 연속 동기화 실패 주기 수이다.
+const watchStaleIntervals = 3
+
+// stale 은 마지막 성공 동기화 이후 watchStaleIntervals 주기가 지났으면 오류를 반환한다.
+func (w *clusterWatch) stale(now time.Time) error {
+	if w.interval <= 0 || w.lastErr == nil {
+		return nil
+	}
+	age := now.Sub(w.lastSync)
+	if age <= watchStaleIntervals*w.interval {
+		return nil
+	}
+	return fmt.Errorf("Vault 클러스터 시크릿이 %s 동안 갱신되지 않았습니다: %w", age.Truncate(time.Second), w.lastErr)
+}
+
+// WatchClusters 는 interval 마다 시크릿 메타데이터 버전을 확인해 바뀐 시크릿만 다시 읽는다.
+// 변경 사항은 events 로 보내며, 받는 쪽이 밀려 있으면 이벤트를 버린다(상태는 GetClusterInfos 로 항상 조회 가능).
+// 첫 동기화가 끝나면 GetClusterInfos 는 Vault 를 조회하지 않고 감시 결과를 반환하며, 동기화가
+// watchStaleIntervals 주기 넘게 실패하면 오류를 반환한다. Close 시 중단된다.
+func (c *Client) WatchClusters(interval time.Duration, events chan<- model.CredentialEvent) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	if c.clusters != nil {
+		return
+	}
+	c.clusters = &clusterWatch{versions: map[string]string{}, creds: map[string]model.ClusterCredential{}, interval: interval}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := c.syncClusters(events); err != nil {
+				log.Printf("Vault 클러스터 시크릿 변경 확인 실패: %v", err)
+			}
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// watchedClusters 는 감시 중이고 한 번 이상 동기화되었으면 감시 결과를 반환한다. 이후 동기화가 계속
+// 실패해 결과가 오래되었으면 마지막 결과와 함께 오류를 반환한다.
+func (c *Client) watchedClusters() ([]model.ClusterCredential, bool, error) {
+	c.watchMu.Lock()
+	w := c.clusters
+	c.watchMu.Unlock()
+	if w == nil {
+		return nil, false, nil
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.synced {
+		return nil, false, nil
+	}
+	return uniqueClusters(w.creds), true, w.stale(time.Now())
+}
+
+// syncClusters 는 전체 목록과 버전을 한 번 확인한다. 목록 조회 중 오류가 나면 이전 상태를 유지하고,
+// 일부 시크릿 읽기에 실패하면 나머지 변경만 반영한 뒤 오류를 반환한다.
+func (c *Client) syncClusters(events chan<- model.CredentialEvent) error {
+	err := c.syncOnce(events)
+
+	c.watchMu.Lock()
+	w := c.clusters
+	c.watchMu.Unlock()
+	w.mu.Lock()
+	if err == nil {
+		w.lastSync = time.Now()
+	}
+	w.lastErr = err
+	w.mu.Unlock()
+	return err
+}
+
+func (c *Client) syncOnce(events chan<- model.CredentialEvent) error {
+	versions := map[string]string{}
+	secrets := map[string]*api.Secret{}
+	if err := c.walkVersions("", 0, versions, secrets); err != nil {
+		return err
+	}
+
+	c.watchMu.Lock()
+	w := c.clusters
+	c.watchMu.Unlock()
+
+	w.mu.RLock()
+	var changed []string
+	for key, version := range versions {
+		if w.versions[key] != version {
+			changed = append(changed, key)
+		}
+	}
+	w.mu.RUnlock()
+
+	// 바뀐 시크릿만 다시 읽는다. 읽기에 실패한 시크릿은 이전 버전과 인증 정보를 유지해 다음 주기에 다시 읽는다.
+	type readResult struct {
+		cred  model.ClusterCredential
+		valid bool
+	}
+	l := c.layout.normalized()
+	fresh := map[string]readResult{}
+	var readErrs []error
+	for _, key := range changed {
+		secret, ok := secrets[key]
+		if !ok {
+			var err error
+			if secret, err = logicalRead(c.api, l.readPath(key)); err != nil {
+				readErrs = append(readErrs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+		}
+		cred, valid := l.credential(key, secret)
+		fresh[key] = readResult{cred: cred, valid: valid}
+	}
+
+	w.mu.Lock()
+	var emitted []model.CredentialEvent
+	for _, key := range changed {
+		result, read := fresh[key]
+		if !read {
+			continue
+		}
+		old, had := w.creds[key]
+		switch {
+		case result.valid && had:
+			w.creds[key] = result.cred
+			emitted = append(emitted, model.CredentialEvent{Type: model.CredentialUpdated, ClusterID: result.cred.ClusterID, Credential: result.cred})
+		case result.valid:
+			w.creds[key] = result.cred
+			emitted = append(emitted, model.CredentialEvent{Type: model.CredentialAdded, ClusterID: result.cred.ClusterID, Credential: result.cred})
+		case had:
+			// 필수 필드가 빠지면 더 이상 수집할 수 없으므로 삭제로 본다.
+			delete(w.creds, key)
+			emitted = append(emitted, model.CredentialEvent{Type: model.CredentialRemoved, ClusterID: old.ClusterID, Credential: old})
+		}
+		w.versions[key] = versions[key]
+	}
+	for key := range w.versions {
+		if _, ok := versions[key]; ok {
+			continue
+		}
+		if old, had := w.creds[key]; had {
+			emitted = append(emitted, model.CredentialEvent{Type: model.CredentialRemoved, ClusterID: old.ClusterID, Credential: old})
+		}
+		delete(w.versions, key)
+		delete(w.creds, key)
+	}
+	w.synced = true
+	w.mu.Unlock()
+
+	for _, ev := range emitted {
+		select {
+		case events <- ev:
+		default:
+		}
+	}
+	return errors.Join(readErrs...)
+}
+
+// walkVersions 는 folder 하위 시크릿의 버전을 versions 에 채운다. "/" 로 끝나는 key 는
+// 메타데이터가 있으면 시크릿으로, 없으면 하위 폴더로 보며, 폴더로 확인된 key 는 버전을 확인하지 않는다.
+// 버전을 구하면서 시크릿 전체를 읽은 경우(KV v1)에는 다시 읽지 않도록 secrets 에 함께 담는다.
+func (c *Client) walkVersions(folder string, depth int, versions map[string]string, secrets map[string]*api.Secret) error {
+	keys, err := c.listKeys(folder)
+	if err != nil {
+		return err
+	}
+	if folder != "" && len(keys) > 0 {
+		c.markFolder(folder)
+	}
+	for _, key := range keys {
+		name, ok := key.(string)
+		if !ok {
+			continue
+		}
+		if !c.isFolder(folder + name) {
+			version, secret, found, err := c.secretVersion(folder + name)
+			if err != nil {
+				return err
+			}
+			if found || !strings.HasSuffix(name, "/") {
+				if found {
+					versions[folder+name] = version
+					if secret != nil {
+						secrets[folder+name] = secret
+					}
+				}
+				continue
+			}
+		}
+		if depth >= maxFolderDepth {
+			log.Printf("Vault 폴더 깊이 초과로 %s%s 하위를 건너뜁니다", folder, name)
+			continue
+		}
+		if err := c.walkVersions(folder+name, depth+1, versions, secrets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secretVersion 은 시크릿의 버전 식별자를 반환한다. KV v2 는 메타데이터의 current_version 과
+// updated_time 을 사용한다. 메타데이터가 없는 KV v1 은 시크릿 전체를 읽어 내용의 해시를 사용하므로
+// 감시 비용이 주기마다 전체를 읽는 것과 같고, 읽은 시크릿을 함께 반환해 변경 시 다시 읽지 않게 한다.
+func (c *Client) secretVersion(key string) (string, *api.Secret, bool, error) {
+	l := c.layout.normalized()
+	if l.Version == 1 {
+		secret, err := logicalRead(c.api, l.readPath(key))
+		if err != nil || secret == nil || secret.Data == nil {
+			return "", nil, false, err
+		}
+		raw, err := json.Marshal(secret.Data)
+		if err != nil {
+			return "", nil, false, err
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(raw)), secret, true, nil
+	}
+
+	meta, err := logicalRead(c.api, l.listPath(key))
+	if err != nil || meta == nil || meta.Data == nil {
+		return "", nil, false, err
+	}
+	version, ok := meta.Data["current_version"]
+	if !ok {
+		return "", nil, false, nil
+	}
+	return fmt.Sprintf("%v@%v", version, meta.Data["updated_time"]), nil, true, nil
+}
@@ -2,44 +2,72 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"federation-metric-api/controller"
 	_ "federation-metric-api/docs"
+	"federation-metric-api/internal/adapter"
+	"federation-metric-api/internal/api"
+	"federation-metric-api/model"
 	"fmt"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	echoSwagger "github.com/swaggo/http-swagger"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 )
 
 var hostClusterName = "host-cluster"
 
+// @title        K-PaaS Federation Collector API
+// @version      1.0
+// @description  페더레이션 호스트·멤버 클러스터의 최신 수집 결과를 조회합니다.
+// @BasePath     /
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	go func() {
 		mux := http.NewServeMux()
-		mux.HandleFunc("/actuator/health/liveness", func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-			fmt.Fprintln(w, "ok")
-		})
-		mux.HandleFunc("/actuator/health/readiness", func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-			fmt.Fprintln(w, "ready")
-		})
+		mux.HandleFunc("/actuator/health/liveness", healthHandler(controller.Liveness))
+		mux.HandleFunc("/actuator/health/readiness", healthHandler(controller.Readiness))
+		api.Register(mux, controller.LatestSnapshot)
+		api.RegisterHistory(mux, controller.History)
+		mux.Handle("/metrics", promhttp.HandlerFor(controller.Registry, promhttp.HandlerOpts{}))
 		mux.Handle("/swagger/", echoSwagger.WrapHandler)
 
 		http.ListenAndServe(":8001", mux)
 	}()
 
-	go controller.RepeatMetric(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		controller.RepeatMetric(ctx)
+	}()
 
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 	<-sig
 	fmt.Println("Shutdown signal received.")
-	//cancel()
-	time.Sleep(2 * time.Second)
+	// 수집 루프가 끝난 뒤에 이력을 저장하고 Vault 토큰을 폐기해 마지막 주기의 기록이 빠지지 않게 한다.
+	cancel()
+	<-done
+	if err := controller.SaveHistory(); err != nil {
+		fmt.Printf("Failed to save history: %v\n", err)
+	}
+	if err := adapter.Close(); err != nil {
+		fmt.Printf("Failed to revoke vault token: %v\n", err)
+	}
+}
+
+// healthHandler 는 Spring actuator 형식의 헬스 응답을 쓰고, 상태가 UP 이 아니면 503 을 반환한다.
+func healthHandler(check func() model.Health) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := check()
+		w.Header().Set("Content-Type", "application/vnd.spring-boot.actuator.v3+json")
+		if health.Status != model.HealthUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(health)
+	}
 }
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// 클러스터 역할
+const (
+	ClusterRoleHost   = "host"
+	ClusterRoleMember = "member"
+)
+
+// ClusterSummary 는 클러스터 목록 조회 API 의 항목이다.
+type ClusterSummary struct {
+	ClusterId       string     `json:"clusterId"`
+	Role            string     `json:"role"`
+	AccessMode      string     `json:"accessMode"`
+	Status          string     `json:"status"`
+	CollectState    string     `json:"collectState"`
+	CollectError    string     `json:"collectError,omitempty"`
+	LastSuccessTime *time.Time `json:"lastSuccessTime,omitempty"`
+}
+
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
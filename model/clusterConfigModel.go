@@ -4,4 +4,29 @@ type ClusterCredential struct {
 	ClusterID    string
 	APIServerURL string
 	BearerToken  string
+	// ClientCert, ClientKey 는 클라이언트 인증서 방식 인증에 사용하는 PEM 형식 인증서와 개인 키이다.
+	ClientCert string
+	ClientKey  string
+	// Kubeconfig 는 exec 플러그인 등 kubeconfig 로만 표현 가능한 인증 방식을 위한 kubeconfig 원문이다.
+	// 값이 있으면 다른 인증 필드보다 우선한다.
+	Kubeconfig string
+	// CACert 는 API 서버 인증서 검증에 사용할 PEM 형식 CA 번들이다. 비어 있으면 시스템 루트 인증서를 사용한다.
+	CACert string
+	// Insecure 는 인증서 검증을 생략하도록 명시적으로 허용된 클러스터인지 나타낸다.
+	Insecure bool
+}
+
+// 클러스터 인증 정보 변경 이벤트 종류
+const (
+	CredentialAdded   = "added"
+	CredentialUpdated = "updated"
+	CredentialRemoved = "removed"
+)
+
+// CredentialEvent 는 인증 정보 소스에서 감지한 클러스터 추가, 변경, 삭제이다.
+// 삭제 이벤트의 Credential 은 마지막으로 알려진 값이다.
+type CredentialEvent struct {
+	Type       string
+	ClusterID  string
+	Credential ClusterCredential
 }
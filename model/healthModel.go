@@ -0,0 +1,18 @@
+package model
+
+// Spring Boot actuator 의 헬스 상태 값
+const (
+	HealthUp   = "UP"
+	HealthDown = "DOWN"
+)
+
+// Health 는 /actuator/health/* 응답 본문이다. 구성 요소 중 하나라도 DOWN 이면 전체 상태도 DOWN 이다.
+type Health struct {
+	Status     string                     `json:"status"`
+	Components map[string]HealthComponent `json:"components,omitempty"`
+}
+
+type HealthComponent struct {
+	Status  string                 `json:"status"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// UsagePoint 는 클러스터 사용률 시계열의 한 점이다. 집계 해상도에서는 Time 부터 한 구간 동안
+// 수집된 Samples 개 값의 평균이다.
+type UsagePoint struct {
+	Time          time.Time      `json:"time"`
+	RealTimeUsage NodeUsageFloat `json:"realTimeUsage"`
+	RequestUsage  NodeUsageFloat `json:"requestUsage"`
+	Samples       int            `json:"samples"`
+}
+
+// ClusterHistory 는 이력 조회 API 의 응답이다.
+type ClusterHistory struct {
+	ClusterId  string       `json:"clusterId"`
+	Resolution string       `json:"resolution"`
+	From       time.Time    `json:"from"`
+	To         time.Time    `json:"to"`
+	Points     []UsagePoint `json:"points"`
+}
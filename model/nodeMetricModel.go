@@ -39,16 +39,167 @@ type MetricStatus struct {
 	Time                time.Time             `json:"time"`
 	HostClusterStatus   HostClusterStatus     `json:"hostClusterStatus"`
 	MemberClusterStatus []MemberClusterStatus `json:"memberClusterStatus"`
+	Reconciliation      Reconciliation        `json:"reconciliation"`
+	KarmadaClusters     []KarmadaClusterView  `json:"karmadaClusters"`
+	// FederatedWorkloads 는 사용량 상위 워크로드이고, FederatedWorkloadCount 는 잘리기 전 워크로드 수이다.
+	FederatedWorkloads     []FederatedWorkload `json:"federatedWorkloads"`
+	FederatedWorkloadCount int                 `json:"federatedWorkloadCount"`
+	Dependencies           []DependencyStatus  `json:"dependencies"`
 }
 
-type HostClusterStatus struct {
-	ClusterId     string         `json:"clusterId"`
-	Status        string         `json:"status"`
-	NodeSummary   NodeSummary    `json:"nodeSummary"`
-	RealTimeUsage NodeUsageFloat `json:"realTimeUsage"`
-	RequestUsage  NodeUsageFloat `json:"requestUsage"`
+// DependencyStatus 는 수집기가 의존하는 외부 서비스(인증 정보 소스, Karmada, NATS)의 최근 호출 결과이다.
+// 실패한 의존성은 NextRetryTime 이후에 다시 호출되며 그 사이에는 마지막으로 받은 데이터를 사용한다.
+type DependencyStatus struct {
+	Name                string     `json:"name"`
+	Healthy             bool       `json:"healthy"`
+	Error               string     `json:"error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastSuccessTime     *time.Time `json:"lastSuccessTime,omitempty"`
+	NextRetryTime       *time.Time `json:"nextRetryTime,omitempty"`
 }
-type MemberClusterStatus struct {
-	ClusterId     string         `json:"clusterId"`
-	RealTimeUsage NodeUsageFloat `json:"realTimeUsage"`
+
+// FederatedWorkload 는 Karmada ResourceBinding 으로 전파된 워크로드의 멤버 클러스터별 배치와
+// 사용량이다. Usage 는 실사용량을 아는 배치의 합계, Requests 는 사용량을 수집한 배치의 합계이다.
+type FederatedWorkload struct {
+	Kind       string              `json:"kind"`
+	Namespace  string              `json:"namespace,omitempty"`
+	Name       string              `json:"name"`
+	Binding    string              `json:"binding"`
+	Replicas   int32               `json:"replicas"`
+	Usage      *NodeResources      `json:"usage,omitempty"`
+	Requests   NodeResources       `json:"requests"`
+	Placements []WorkloadPlacement `json:"placements"`
+}
+
+// WorkloadPlacement 는 워크로드가 배치된 멤버 클러스터 하나의 레플리카 수와 실행 중인 파드 사용량이다.
+// 멤버 클러스터의 네임스페이스 사용량이나 파드 지표를 수집하지 못했으면 Usage 가 비어 있다.
+type WorkloadPlacement struct {
+	Cluster  string         `json:"cluster"`
+	Replicas int32          `json:"replicas"`
+	Pods     int            `json:"pods"`
+	Usage    *NodeResources `json:"usage,omitempty"`
+	Requests NodeResources  `json:"requests"`
+}
+
+// KarmadaClusterView 는 Karmada Cluster 오브젝트에 기록된 멤버 클러스터 상태이다.
+type KarmadaClusterView struct {
+	Name              string            `json:"name"`
+	SyncMode          string            `json:"syncMode"`
+	KubernetesVersion string            `json:"kubernetesVersion"`
+	Ready             string            `json:"ready"`
+	ReadyReason       string            `json:"readyReason,omitempty"`
+	ReadyMessage      string            `json:"readyMessage,omitempty"`
+	Allocatable       map[string]string `json:"allocatable,omitempty"`
+	Allocating        map[string]string `json:"allocating,omitempty"`
+	Allocated         map[string]string `json:"allocated,omitempty"`
+}
+
+// Reconciliation 은 Karmada 멤버 클러스터와 클러스터 인증 정보의 매칭 결과이다.
+type Reconciliation struct {
+	Matched              []ClusterMatch     `json:"matched"`
+	MissingCredentials   []string           `json:"missingCredentials"`
+	UnregisteredClusters []string           `json:"unregisteredClusters"`
+	EndpointMismatches   []EndpointMismatch `json:"endpointMismatches"`
+}
+
+// 매칭 기준
+const (
+	MatchedByName     = "name"
+	MatchedByEndpoint = "endpoint"
+)
+
+type ClusterMatch struct {
+	ClusterId   string `json:"clusterId"`
+	KarmadaName string `json:"karmadaName"`
+	MatchedBy   string `json:"matchedBy"`
+}
+
+type EndpointMismatch struct {
+	ClusterId          string `json:"clusterId"`
+	KarmadaEndpoint    string `json:"karmadaEndpoint"`
+	CredentialEndpoint string `json:"credentialEndpoint"`
+}
+
+// 클러스터별 수집 결과 상태
+const (
+	CollectStateOk                = "ok"
+	CollectStateDegraded          = "degraded"
+	CollectStateUnreachable       = "unreachable"
+	CollectStateUnauthorized      = "unauthorized"
+	CollectStateMetricsAPIMissing = "metrics-api-missing"
+	CollectStateTimeout           = "timeout"
+)
+
+// 멤버 클러스터 API 서버 접근 경로
+const (
+	AccessModeDirect       = "direct"
+	AccessModeKarmadaProxy = "karmada-proxy"
+)
+
+// ClusterStatus 는 호스트와 멤버 클러스터에 공통으로 수집되는 상태 정보이다.
+type ClusterStatus struct {
+	ClusterId       string         `json:"clusterId"`
+	AccessMode      string         `json:"accessMode"`
+	InsecureTLS     bool           `json:"insecureTls"`
+	CollectState    string         `json:"collectState"`
+	CollectError    string         `json:"collectError,omitempty"`
+	LastSuccessTime *time.Time     `json:"lastSuccessTime,omitempty"`
+	NextRetryTime   *time.Time     `json:"nextRetryTime,omitempty"`
+	Status          string         `json:"status"`
+	NodeSummary     NodeSummary    `json:"nodeSummary"`
+	RealTimeUsage   NodeUsageFloat `json:"realTimeUsage"`
+	RequestUsage    NodeUsageFloat `json:"requestUsage"`
+	// RealTimeUsageCollected, RequestUsageCollected 는 이번 수집에서 각 사용률을 얻었는지이다.
+	// false 이면 사용률 값은 0 이 아니라 알 수 없는 값이다.
+	RealTimeUsageCollected bool `json:"realTimeUsageCollected"`
+	RequestUsageCollected  bool `json:"requestUsageCollected"`
+	// Nodes 는 노드별 상태이다. REST API 로만 제공하며 NATS KV 스냅샷에는 넣지 않는다.
+	Nodes []NodeStatus `json:"nodes,omitempty"`
+	// Namespaces 는 사용량 상위 네임스페이스이고, NamespaceCount 는 잘리기 전 네임스페이스 수이다.
+	Namespaces     []NamespaceUsage `json:"namespaces,omitempty"`
+	NamespaceCount int              `json:"namespaceCount,omitempty"`
+}
+
+type HostClusterStatus = ClusterStatus
+type MemberClusterStatus = ClusterStatus
+
+// PodMetricModel 은 metrics.k8s.io/v1beta1 pods 응답이다.
+type PodMetricModel struct {
+	Items []PodMetricItem `json:"items"`
+}
+
+type PodMetricItem struct {
+	Metadata   PodMetricMetadata     `json:"metadata"`
+	Containers []ContainerMetricItem `json:"containers"`
+}
+
+type PodMetricMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type ContainerMetricItem struct {
+	Name  string          `json:"name"`
+	Usage NodeUsageString `json:"usage"`
+}
+
+// NamespaceUsage 는 네임스페이스의 Running 파드 실사용량과 requests/limits 합계이다.
+// 파드 지표가 없으면 Usage 가 비어 있다.
+type NamespaceUsage struct {
+	Namespace string          `json:"namespace"`
+	Pods      int             `json:"pods"`
+	Usage     *NodeResources  `json:"usage,omitempty"`
+	Requests  NodeResources   `json:"requests"`
+	Limits    NodeResources   `json:"limits"`
+	Workloads []WorkloadUsage `json:"workloads,omitempty"`
+}
+
+// WorkloadUsage 는 Deployment, StatefulSet 등 파드를 소유한 워크로드 단위 사용량이다.
+type WorkloadUsage struct {
+	Kind     string         `json:"kind"`
+	Name     string         `json:"name"`
+	Pods     int            `json:"pods"`
+	Usage    *NodeResources `json:"usage,omitempty"`
+	Requests NodeResources  `json:"requests"`
+	Limits   NodeResources  `json:"limits"`
 }
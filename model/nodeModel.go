@@ -70,6 +70,25 @@ type NodeStateInfo struct {
 	UpdateTime string  `sql:"update_time"`
 }
 
+// NodeStatus 는 스냅샷에 포함되는 노드별 상태이다. CPU 는 코어 수, 메모리는 바이트 단위이며
+// UsageRatio 는 클러스터 사용률과 같이 용량(capacity) 대비 실사용량 백분율이다.
+// metrics.k8s.io 에 사용량이 없는 노드는 Usage, UsageRatio 가 비어 있다.
+type NodeStatus struct {
+	NodeName       string          `json:"nodeName"`
+	Ready          string          `json:"ready"`
+	KubeletVersion string          `json:"kubeletVersion"`
+	Architecture   string          `json:"architecture"`
+	Capacity       NodeResources   `json:"capacity"`
+	Allocatable    NodeResources   `json:"allocatable"`
+	Usage          *NodeResources  `json:"usage,omitempty"`
+	UsageRatio     *NodeUsageFloat `json:"usageRatio,omitempty"`
+}
+
+type NodeResources struct {
+	Cpu    float64 `json:"cpu"`
+	Memory int64   `json:"memory"`
+}
+
 type ContainerStatus struct {
 	Name  string `json:"name"`
 	Ready bool   `json:"ready"`